@@ -0,0 +1,176 @@
+package gvm
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// ActivationMode defines how SetCurrent places the executable of the active version at
+// the stable path ReleasesPath/bin/<name>
+type ActivationMode string
+
+const (
+	// ActivationSymlink links the stable path to the executable with a symbolic link
+	ActivationSymlink ActivationMode = "symlink"
+	// ActivationCopy copies the executable to the stable path, for filesystems and
+	// platforms which do not support links
+	ActivationCopy ActivationMode = "copy"
+	// ActivationHardlink links the stable path to the executable with a hard link
+	ActivationHardlink ActivationMode = "hardlink"
+)
+
+// activeFolder is the folder in ReleasesPath containing the executable of the active version
+const activeFolder = "bin"
+
+// defaultActivationMode returns the ActivationMode used when one is not set, symbolic links
+// are not available to all users on Windows so the executable is copied
+func defaultActivationMode(goos string) ActivationMode {
+	if goos == "windows" {
+		return ActivationCopy
+	}
+
+	return ActivationSymlink
+}
+
+// activePath returns the stable path of the executable of the active version, the path
+// does not depend on the version so that it can be added to the PATH
+func (v *VersionsImpl) activePath() string {
+	return path.Join(v.options.ReleasesPath, activeFolder, v.activeName())
+}
+
+// activeName returns the file name of the executable of the active version, SavedBinaryName
+// when set otherwise the repo name with the .exe extension on Windows
+func (v *VersionsImpl) activeName() string {
+	if v.options.SavedBinaryName != "" {
+		return v.options.SavedBinaryName
+	}
+
+	if v.options.GOOS == "windows" {
+		return v.options.Repo + ".exe"
+	}
+
+	return v.options.Repo
+}
+
+// activate places the executable for the tag at the stable path using ActivationMode,
+// the executable is created alongside the stable path then renamed so that it is replaced
+// atomically
+func (v *VersionsImpl) activate(tag string) error {
+	src := v.exePath(tag)
+	dst := v.activePath()
+
+	err := os.MkdirAll(path.Dir(dst), os.ModePerm)
+	if err != nil {
+		return xerrors.Errorf("Unable to create folder for the active version: %w", err)
+	}
+
+	tmp, err := tempPath(path.Dir(dst), path.Base(dst))
+	if err != nil {
+		return xerrors.Errorf("Unable to activate %s: %w", tag, err)
+	}
+
+	switch mode := v.options.ActivationMode; mode {
+	case ActivationSymlink:
+		// use a relative link so that it remains valid when ReleasesPath is moved
+		var target string
+		target, err = filepath.Rel(path.Dir(dst), src)
+		if err == nil {
+			err = os.Symlink(target, tmp)
+		}
+	case ActivationHardlink:
+		err = os.Link(src, tmp)
+	case ActivationCopy:
+		err = copyFile(src, tmp)
+	default:
+		return xerrors.Errorf("Unknown activation mode %q", mode)
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return xerrors.Errorf("Unable to activate %s: %w", tag, err)
+	}
+
+	err = os.Rename(tmp, dst)
+	if err != nil {
+		os.Remove(tmp)
+		return xerrors.Errorf("Unable to activate %s: %w", tag, err)
+	}
+
+	return nil
+}
+
+// tempPath returns a unique path in dir for a temporary file which is renamed over a file
+// with the given name, the file does not exist so that a link can be created at the path
+func tempPath(dir, name string) (string, error) {
+	f, err := ioutil.TempFile(dir, "."+name+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+
+	f.Close()
+	os.Remove(f.Name())
+
+	return f.Name(), nil
+}
+
+// writeFileAtomic writes the data to a unique temporary file alongside fp and renames it
+// over fp so that readers never see a partially written file, concurrent writers each use
+// their own temporary file
+func writeFileAtomic(fp string, data []byte, perm os.FileMode) error {
+	f, err := ioutil.TempFile(path.Dir(fp), "."+path.Base(fp)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), fp)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// copyFile copies the file src to dst keeping the permissions of src
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,65 @@
+package gvm
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Platform is the operating system and architecture of the host
+type Platform struct {
+	// OS and Arch are the names most commonly used in asset names, e.g. x86_64 for amd64
+	OS   string
+	Arch string
+	// GOOS and GOARCH are the values reported by the Go runtime
+	GOOS   string
+	GOARCH string
+}
+
+// archAssetNames are the names most commonly used in asset names for each GOARCH
+var archAssetNames = map[string]string{
+	"amd64": "x86_64",
+	"386":   "i386",
+	"arm":   "armv7",
+}
+
+// DetectPlatform returns the platform of the host, on Apple Silicon Arch is arm64 even
+// when the process is running under Rosetta
+func DetectPlatform() Platform {
+	return platformFor(runtime.GOOS, runtime.GOARCH)
+}
+
+// platformFor returns the platform for the given GOOS and GOARCH
+func platformFor(goos, goarch string) Platform {
+	arch := goarch
+	if native := detectNativeArch(goos, goarch); native != "" {
+		arch = native
+	}
+
+	if name, ok := archAssetNames[arch]; ok {
+		arch = name
+	}
+
+	return Platform{OS: goos, Arch: arch, GOOS: goos, GOARCH: goarch}
+}
+
+// sysctl returns the value of the named kernel state, it is a variable so
+// that tests can replace it
+var sysctl = func(name string) (string, error) {
+	out, err := exec.Command("sysctl", "-n", name).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// detectNativeArch returns arm64 when goos is darwin, goarch is amd64 and the hardware
+// is Apple Silicon e.g. when running under Rosetta, otherwise an empty string is returned
+func detectNativeArch(goos, goarch string) string {
+	if goos != "darwin" || goarch != "amd64" {
+		return ""
+	}
+
+	if out, err := sysctl("hw.optional.arm64"); err == nil && out == "1" {
+		return "arm64"
+	}
+
+	return ""
+}
@@ -0,0 +1,92 @@
+package gvm
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func mockSysctl(t *testing.T, values map[string]string) {
+	original := sysctl
+	t.Cleanup(func() { sysctl = original })
+
+	sysctl = func(name string) (string, error) {
+		v, ok := values[name]
+		if !ok {
+			return "", xerrors.Errorf("unknown oid %s", name)
+		}
+
+		return v, nil
+	}
+}
+
+func TestDetectNativeArchReturnsArm64OnAppleSilicon(t *testing.T) {
+	mockSysctl(t, map[string]string{"hw.optional.arm64": "1"})
+
+	assert.Equal(t, "arm64", detectNativeArch("darwin", "amd64"))
+	assert.Equal(t, "", detectNativeArch("darwin", "arm64"))
+	assert.Equal(t, "", detectNativeArch("linux", "amd64"))
+}
+
+func TestDetectNativeArchReturnsEmptyOnIntel(t *testing.T) {
+	mockSysctl(t, map[string]string{"hw.optional.arm64": "0"})
+	assert.Equal(t, "", detectNativeArch("darwin", "amd64"))
+
+	// older versions of macOS do not have the oid
+	mockSysctl(t, map[string]string{})
+	assert.Equal(t, "", detectNativeArch("darwin", "amd64"))
+}
+
+func TestNewDetectsNativeArch(t *testing.T) {
+	mockSysctl(t, map[string]string{"hw.optional.arm64": "1"})
+
+	v := New(Options{GOOS: "darwin", GOARCH: "amd64", DetectNativeArch: true}).(*VersionsImpl)
+	assert.Equal(t, "arm64", v.nativeArch)
+
+	v = New(Options{GOOS: "darwin", GOARCH: "amd64"}).(*VersionsImpl)
+	assert.Equal(t, "", v.nativeArch)
+}
+
+func TestListReleasesPrefersNativeArchAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.GOOS = "darwin"
+	v.options.GOARCH = "amd64"
+	v.nativeArch = "arm64"
+	v.options.AssetNameFunc = func(ver, goos, goarch string) string {
+		return fmt.Sprintf("fake-service-%s-%s", goos, goarch)
+	}
+	s.addRelease("v0.14.2", "fake-service-darwin-amd64", "fake-service-darwin-arm64")
+	s.addRelease("v0.14.1", "fake-service-darwin-amd64")
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.2/fake-service-darwin-arm64", r["v0.14.2"])
+	// falls back to the translated asset
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-darwin-amd64", r["v0.14.1"])
+}
+
+func TestPlatformForMapsArchToAssetNames(t *testing.T) {
+	mockSysctl(t, map[string]string{})
+
+	assert.Equal(t, Platform{OS: "linux", Arch: "x86_64", GOOS: "linux", GOARCH: "amd64"}, platformFor("linux", "amd64"))
+	assert.Equal(t, Platform{OS: "linux", Arch: "arm64", GOOS: "linux", GOARCH: "arm64"}, platformFor("linux", "arm64"))
+	assert.Equal(t, Platform{OS: "windows", Arch: "i386", GOOS: "windows", GOARCH: "386"}, platformFor("windows", "386"))
+}
+
+func TestPlatformForUsesNativeArchOnAppleSilicon(t *testing.T) {
+	mockSysctl(t, map[string]string{"hw.optional.arm64": "1"})
+
+	assert.Equal(t, Platform{OS: "darwin", Arch: "arm64", GOOS: "darwin", GOARCH: "amd64"}, platformFor("darwin", "amd64"))
+}
+
+func TestDetectPlatformReturnsRuntimeValues(t *testing.T) {
+	p := DetectPlatform()
+
+	assert.Equal(t, runtime.GOOS, p.GOOS)
+	assert.Equal(t, runtime.GOARCH, p.GOARCH)
+	assert.NotEmpty(t, p.Arch)
+}
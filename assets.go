@@ -0,0 +1,188 @@
+package gvm
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+// ErrNoMatchingAsset is returned when no asset in a release matches the predicate
+var ErrNoMatchingAsset = xerrors.New("No asset matches")
+
+// ErrMultipleAssets is returned when more than one asset in a release matches the predicate
+var ErrMultipleAssets = xerrors.New("More than one asset matches")
+
+// Asset is the metadata for a file attached to a GitHub release
+type Asset struct {
+	ID          int64
+	Name        string
+	ContentType string
+	Size        int64
+	// URL is the download URL of the asset
+	URL       string
+	UpdatedAt time.Time
+}
+
+// AssetMatchFunc returns true when the asset with the given name, content type and size should be selected
+type AssetMatchFunc func(name, contentType string, size int64) bool
+
+// FindAssets returns the assets for the release with the given tag which match the predicate,
+// in the order they are listed by GitHub
+func (v *VersionsImpl) FindAssets(tag string, match AssetMatchFunc) ([]Asset, error) {
+	r, err := v.GetRawRelease(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []Asset{}
+	for _, a := range r.Assets {
+		if match(a.GetName(), a.GetContentType(), int64(a.GetSize())) {
+			assets = append(assets, newAsset(a))
+		}
+	}
+
+	return assets, nil
+}
+
+// DownloadAssetMatching downloads and uncompresses the single asset for the release with the
+// given tag which matches the predicate, returning the path of the executable.
+// Returns ErrNoMatchingAsset or ErrMultipleAssets when there is not exactly one match
+func (v *VersionsImpl) DownloadAssetMatching(tag string, match AssetMatchFunc) (string, error) {
+	assets, err := v.FindAssets(tag, match)
+	if err != nil {
+		return "", err
+	}
+
+	if len(assets) == 0 {
+		return "", xerrors.Errorf("%w: release %s", ErrNoMatchingAsset, tag)
+	}
+
+	if len(assets) > 1 {
+		return "", xerrors.Errorf("%w: release %s has %d matching assets", ErrMultipleAssets, tag, len(assets))
+	}
+
+	return v.DownloadRelease(tag, assets[0].URL)
+}
+
+// DownloadReleaseAssets downloads every asset for the release with the given tag whose name matches
+// the glob pattern into destDir, returning the path of each downloaded asset keyed by name.
+// Assets are saved as published without being uncompressed and are downloaded in parallel
+// bounded by Concurrency. When an asset fails to download the paths of the assets which were
+// downloaded are returned along with the error
+func (v *VersionsImpl) DownloadReleaseAssets(tag, glob, destDir string) (map[string]string, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, xerrors.Errorf("Invalid glob %s: %w", glob, err)
+	}
+
+	r, err := v.GetRawRelease(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(destDir, os.ModePerm)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to create destination folder: %w", err)
+	}
+
+	matched := []github.ReleaseAsset{}
+	for _, a := range r.Assets {
+		if ok, _ := path.Match(glob, a.GetName()); ok {
+			matched = append(matched, a)
+		}
+	}
+
+	results := make([]string, len(matched))
+	errs := parallel(len(matched), v.options.Concurrency, func(i int) error {
+		fp, _, err := v.fetch(context.Background(), destDir, matched[i].GetBrowserDownloadURL(), nil)
+		if err != nil {
+			if fp != "" {
+				os.Remove(fp)
+			}
+
+			return xerrors.Errorf("Unable to download asset %s: %w", matched[i].GetName(), err)
+		}
+
+		results[i] = fp
+		return nil
+	})
+
+	// the error returned is the first in the order of the release assets
+	paths := map[string]string{}
+	var firstErr error
+
+	for i, a := range matched {
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+
+			continue
+		}
+
+		paths[a.GetName()] = results[i]
+	}
+
+	return paths, firstErr
+}
+
+// DiffAssets compares the asset names of the releases with the given tags, returning the names
+// of the assets in tagB which are not in tagA and the names of the assets in tagA which are not
+// in tagB, both sorted by name
+func (v *VersionsImpl) DiffAssets(tagA, tagB string) ([]string, []string, error) {
+	a, err := v.assetNames(tagA)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err := v.assetNames(tagB)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return missing(a, b), missing(b, a), nil
+}
+
+// assetNames returns the set of asset names for the release with the given tag
+func (v *VersionsImpl) assetNames(tag string) (map[string]bool, error) {
+	r, err := v.GetRawRelease(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, a := range r.Assets {
+		names[a.GetName()] = true
+	}
+
+	return names, nil
+}
+
+// missing returns the sorted names in b which are not in a
+func missing(a, b map[string]bool) []string {
+	names := []string{}
+	for n := range b {
+		if !a[n] {
+			names = append(names, n)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func newAsset(a github.ReleaseAsset) Asset {
+	return Asset{
+		ID:          a.GetID(),
+		Name:        a.GetName(),
+		ContentType: a.GetContentType(),
+		Size:        int64(a.GetSize()),
+		URL:         a.GetBrowserDownloadURL(),
+		UpdatedAt:   a.GetUpdatedAt().Time,
+	}
+}
@@ -0,0 +1,146 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestFindAssetsReturnsMatchingAssets(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-darwin", "checksums.txt")
+
+	a, err := v.FindAssets("v0.14.1", func(name, contentType string, size int64) bool {
+		return strings.HasPrefix(name, "fake-service-")
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, a, 2)
+	assert.Equal(t, "fake-service-linux", a[0].Name)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", a[0].URL)
+	assert.Equal(t, "fake-service-darwin", a[1].Name)
+}
+
+func TestFindAssetsReturnsEmptyWhenNoMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	a, err := v.FindAssets("v0.14.1", func(name, contentType string, size int64) bool { return false })
+	assert.NoError(t, err)
+
+	assert.Len(t, a, 0)
+}
+
+func TestFindAssetsReturnsErrorWhenReleaseNotFound(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.FindAssets("v0.14.1", func(name, contentType string, size int64) bool { return true })
+	assert.Error(t, err)
+}
+
+func TestDownloadAssetMatchingDownloadsAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "checksums.txt")
+
+	fp, err := v.DownloadAssetMatching("v0.14.1", func(name, contentType string, size int64) bool {
+		return name == "fake-service-linux"
+	})
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+}
+
+func TestDownloadAssetMatchingReturnsErrorWhenNoMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadAssetMatching("v0.14.1", func(name, contentType string, size int64) bool { return false })
+	assert.True(t, xerrors.Is(err, ErrNoMatchingAsset))
+}
+
+func TestDownloadAssetMatchingReturnsErrorWhenMultipleMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-darwin")
+
+	_, err := v.DownloadAssetMatching("v0.14.1", func(name, contentType string, size int64) bool { return true })
+	assert.True(t, xerrors.Is(err, ErrMultipleAssets))
+}
+
+func TestDiffAssetsReturnsAddedAndRemovedAssets(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-windows.exe", "checksums.txt")
+	s.addRelease("v0.14.2", "fake-service-linux", "fake-service-linux-arm64", "fake-service-darwin-arm64", "checksums.txt")
+
+	added, removed, err := v.DiffAssets("v0.14.1", "v0.14.2")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"fake-service-darwin-arm64", "fake-service-linux-arm64"}, added)
+	assert.Equal(t, []string{"fake-service-windows.exe"}, removed)
+}
+
+func TestDiffAssetsReturnsErrorWhenReleaseNotFound(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, _, err := v.DiffAssets("v0.14.1", "v0.14.2")
+	assert.Error(t, err)
+}
+
+func TestDownloadReleaseAssetsDownloadsMatchingAssets(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux.tar.gz", "fake-service-darwin.tar.gz", "checksums.txt")
+	dest := path.Join(tmp, "mirror")
+
+	paths, err := v.DownloadReleaseAssets("v0.14.1", "*.tar.gz", dest)
+	assert.NoError(t, err)
+
+	assert.Len(t, paths, 2)
+	assert.Equal(t, path.Join(dest, "fake-service-linux.tar.gz"), paths["fake-service-linux.tar.gz"])
+	assert.Equal(t, path.Join(dest, "fake-service-darwin.tar.gz"), paths["fake-service-darwin.tar.gz"])
+	assert.NoFileExists(t, path.Join(dest, "checksums.txt"))
+
+	d, err := ioutil.ReadFile(paths["fake-service-linux.tar.gz"])
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-service-linux.tar.gz", string(d))
+}
+
+func TestDownloadReleaseAssetsReturnsErrorWhenAssetFails(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-darwin")
+	delete(s.assets, "/download/v0.14.1/fake-service-darwin")
+	dest := path.Join(tmp, "mirror")
+
+	paths, err := v.DownloadReleaseAssets("v0.14.1", "fake-service-*", dest)
+	assert.Error(t, err)
+
+	assert.Contains(t, paths, "fake-service-linux")
+	assert.NoFileExists(t, path.Join(dest, "fake-service-darwin"))
+}
+
+func TestDownloadReleaseAssetsReturnsErrorForInvalidGlob(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadReleaseAssets("v0.14.1", "[", path.Join(tmp, "mirror"))
+	assert.Error(t, err)
+}
+
+func TestDownloadReleaseAssetsReturnsErrorForFirstFailedAssetInReleaseOrder(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-a", "fake-service-b", "fake-service-c")
+	delete(s.assets, "/download/v0.14.1/fake-service-a")
+	delete(s.assets, "/download/v0.14.1/fake-service-c")
+
+	for i := 0; i < 5; i++ {
+		paths, err := v.DownloadReleaseAssets("v0.14.1", "fake-service-*", path.Join(tmp, "mirror"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "fake-service-a")
+
+		assert.Len(t, paths, 1)
+		assert.Contains(t, paths, "fake-service-b")
+	}
+}
@@ -0,0 +1,59 @@
+package gvm
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff defines the delay between retries of a GitHub request
+type Backoff interface {
+	// NextDelay returns the delay before the given retry, attempt is 0 for the first retry
+	NextDelay(attempt int) time.Duration
+}
+
+// ExponentialBackoff doubles the delay for each retry starting at Initial
+type ExponentialBackoff struct {
+	// Initial is the delay before the first retry
+	Initial time.Duration
+	// Max when set is the largest delay returned before jitter is applied
+	Max time.Duration
+	// Jitter is the fraction of the delay randomly added to or subtracted from each delay
+	Jitter float64
+}
+
+// NextDelay returns Initial * 2^attempt adjusted by Jitter
+func (e ExponentialBackoff) NextDelay(attempt int) time.Duration {
+	delay := time.Duration(math.MaxInt64)
+	if d := float64(e.Initial) * math.Pow(2, float64(attempt)); d < math.MaxInt64 {
+		delay = time.Duration(d)
+	}
+
+	if e.Max > 0 && delay > e.Max {
+		delay = e.Max
+	}
+
+	return jitter(delay, e.Jitter)
+}
+
+// ConstantBackoff uses the same delay for every retry
+type ConstantBackoff struct {
+	// Delay is the delay before each retry
+	Delay time.Duration
+	// Jitter is the fraction of the delay randomly added to or subtracted from each delay
+	Jitter float64
+}
+
+// NextDelay returns Delay adjusted by Jitter
+func (c ConstantBackoff) NextDelay(attempt int) time.Duration {
+	return jitter(c.Delay, c.Jitter)
+}
+
+// backoff returns the configured Backoff, defaulting to ExponentialBackoff using
+// RetryBackoff and RetryJitter
+func (v *VersionsImpl) backoff() Backoff {
+	if v.options.Backoff != nil {
+		return v.options.Backoff
+	}
+
+	return ExponentialBackoff{Initial: v.options.RetryBackoff, Jitter: v.options.RetryJitter}
+}
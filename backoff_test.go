@@ -0,0 +1,64 @@
+package gvm
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponentialBackoffDoublesDelay(t *testing.T) {
+	b := ExponentialBackoff{Initial: time.Second}
+
+	assert.Equal(t, time.Second, b.NextDelay(0))
+	assert.Equal(t, 2*time.Second, b.NextDelay(1))
+	assert.Equal(t, 8*time.Second, b.NextDelay(3))
+}
+
+func TestExponentialBackoffIsLimitedToMax(t *testing.T) {
+	b := ExponentialBackoff{Initial: time.Second, Max: 5 * time.Second}
+
+	assert.Equal(t, 5*time.Second, b.NextDelay(3))
+	assert.Equal(t, 5*time.Second, b.NextDelay(100))
+}
+
+func TestExponentialBackoffAppliesJitter(t *testing.T) {
+	b := ExponentialBackoff{Initial: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		d := b.NextDelay(2)
+
+		assert.GreaterOrEqual(t, int64(d), int64(2*time.Second))
+		assert.LessOrEqual(t, int64(d), int64(6*time.Second))
+	}
+}
+
+func TestConstantBackoffReturnsSameDelay(t *testing.T) {
+	b := ConstantBackoff{Delay: time.Second}
+
+	assert.Equal(t, time.Second, b.NextDelay(0))
+	assert.Equal(t, time.Second, b.NextDelay(5))
+}
+
+type recordingBackoff struct {
+	attempts []int
+}
+
+func (r *recordingBackoff) NextDelay(attempt int) time.Duration {
+	r.attempts = append(r.attempts, attempt)
+	return 0
+}
+
+func TestListReleasesUsesConfiguredBackoff(t *testing.T) {
+	_, v, s := setupStub(t)
+	b := &recordingBackoff{}
+	v.options.Backoff = b
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.releaseErrors = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+
+	_, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []int{0, 1}, b.attempts)
+}
@@ -0,0 +1,90 @@
+package gvm
+
+import (
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// blobsFolder is the folder in ReleasesPath which contains the content addressed store
+const blobsFolder = ".blobs"
+
+// cachedBlob returns the path of the asset for the tag in the content addressed store
+// along with the file name of the asset from the URL. An empty path is returned when
+// ContentAddressedStore is not set, there is no checksum for the asset or the asset
+// is not in the store
+func (v *VersionsImpl) cachedBlob(tag, src string) (string, string, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", "", xerrors.Errorf("Invalid URL %s: %w", src, err)
+	}
+
+	name := path.Base(u.Path)
+
+	if !v.options.ContentAddressedStore {
+		return "", name, nil
+	}
+
+	sum := v.expectedSHA256(tag)
+	if sum == "" {
+		return "", name, nil
+	}
+
+	if _, err := os.Stat(v.blobPath(sum)); err != nil {
+		return "", name, nil
+	}
+
+	return v.blobPath(sum), name, nil
+}
+
+// storeBlob moves the downloaded asset into the content addressed store and returns
+// its path in the store, when the store already contains the asset the download is removed
+func (v *VersionsImpl) storeBlob(asset string, d digests) (string, error) {
+	sum, err := d.sum("sha256", asset)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to read downloaded file: %w", err)
+	}
+
+	bp := v.blobPath(hex.EncodeToString(sum))
+	if _, err := os.Stat(bp); err == nil {
+		return bp, os.Remove(asset)
+	}
+
+	err = os.MkdirAll(path.Dir(bp), os.ModePerm)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to create blob folder: %w", err)
+	}
+
+	err = os.Rename(asset, bp)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to move downloaded file: %w", err)
+	}
+
+	return bp, nil
+}
+
+// blobPath returns the path of the asset with the given sha256 in the content addressed store
+func (v *VersionsImpl) blobPath(sum string) string {
+	return path.Join(v.options.ReleasesPath, blobsFolder, sum)
+}
+
+// linkBlob creates a hard link to the blob at dst, falling back to a symbolic
+// link when the file system does not support hard links
+func linkBlob(blob, dst string) error {
+	os.Remove(dst)
+
+	err := os.Link(blob, dst)
+	if err == nil {
+		return nil
+	}
+
+	err = os.Symlink(blob, dst)
+	if err != nil {
+		return xerrors.Errorf("Unable to link blob: %w", err)
+	}
+
+	return nil
+}
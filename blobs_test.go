@@ -0,0 +1,49 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentAddressedStoreLinksIdenticalAssets(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.ContentAddressedStore = true
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return "59f2d4f597005c9030cd4a90e9e622356561e3751d473f247ec9b151e1ab5393"
+	}
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+
+	fp1, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, path.Join(tmp, ".blobs", "59f2d4f597005c9030cd4a90e9e622356561e3751d473f247ec9b151e1ab5393"))
+
+	// the asset is not downloaded again as it is in the store
+	delete(s.assets, "/download/v0.14.2/fake-service-linux")
+
+	fp2, err := v.DownloadRelease("v0.14.2", s.URL+"/download/v0.14.2/fake-service-linux")
+	assert.NoError(t, err)
+
+	fi1, _ := os.Stat(fp1)
+	fi2, _ := os.Stat(fp2)
+	assert.True(t, os.SameFile(fi1, fi2))
+}
+
+func TestContentAddressedStoreUncompressesArchives(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.ContentAddressedStore = true
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{"fake-service-linux": "binary"})
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+
+	blobs, _ := ioutil.ReadDir(path.Join(tmp, ".blobs"))
+	assert.Len(t, blobs, 1)
+}
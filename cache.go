@@ -0,0 +1,157 @@
+package gvm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// releaseCache holds the releases listed for each constraint
+type releaseCache struct {
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	releases map[string]string
+	fetched  time.Time
+	// warm entries are kept up to date by a background refresh and do not expire
+	warm bool
+}
+
+func newReleaseCache() *releaseCache {
+	return &releaseCache{entries: map[string]cacheEntry{}}
+}
+
+// get returns a copy of the releases cached for the constraint, entries which
+// are older than ttl are not returned unless they are warm
+func (c *releaseCache) get(constraint string, ttl time.Duration) (map[string]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[constraint]
+	if !ok || (!e.warm && time.Since(e.fetched) > ttl) {
+		return nil, false
+	}
+
+	r := map[string]string{}
+	for k, v := range e.releases {
+		r[k] = v
+	}
+
+	return r, true
+}
+
+// set caches the releases for the constraint
+func (c *releaseCache) set(constraint string, releases map[string]string, warm bool) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[constraint] = cacheEntry{releases: releases, fetched: time.Now(), warm: warm}
+}
+
+// delete removes the cached releases for the constraint
+func (c *releaseCache) delete(constraint string) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.entries, constraint)
+}
+
+// Warm lists the releases from GitHub once and caches the releases matching each of the
+// constraints so that ListReleases and GetLatestReleaseURL for the constraints do not need to
+// call GitHub. The cached releases expire after CacheTTL, when CacheTTL is not set they do
+// not expire. When no constraints are given the releases for any version are cached
+func (v *VersionsImpl) Warm(ctx context.Context, constraints ...string) error {
+	if v.options.Frozen {
+		return nil
+	}
+
+	if len(constraints) == 0 {
+		constraints = []string{""}
+	}
+
+	var gr []*github.RepositoryRelease
+	if !v.options.UseTags {
+		var err error
+		gr, err = v.listGitHubReleasesContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, c := range constraints {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		c = v.constraint(c)
+
+		var r map[string]string
+		var err error
+		if v.options.UseTags {
+			r, err = v.listTagReleasesChecked(ctx, c)
+		} else {
+			r, err = v.filterReleases(ctx, gr, c)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		v.cache.set(c, r, v.options.CacheTTL == 0)
+	}
+
+	return nil
+}
+
+// StartBackgroundRefresh lists the releases matching the constraint immediately and then
+// at every interval, caching the result so that ListReleases and GetLatestReleaseURL for
+// the constraint do not need to call GitHub. Errors refreshing the cache are ignored and
+// the previous releases are kept. When ctx is done the refresh stops and the cached
+// releases for the constraint are removed. The refresh is not started when Frozen is set
+func (v *VersionsImpl) StartBackgroundRefresh(ctx context.Context, constraint string, interval time.Duration) {
+	if v.options.Frozen {
+		return
+	}
+
+	constraint = v.constraint(constraint)
+
+	refresh := func() {
+		r, err := v.listReleases(ctx, constraint)
+		if err == nil {
+			v.cache.set(constraint, r, true)
+		}
+	}
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		refresh()
+
+		for {
+			select {
+			case <-ctx.Done():
+				v.cache.delete(constraint)
+				return
+			case <-t.C:
+				refresh()
+			}
+		}
+	}()
+}
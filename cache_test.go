@@ -0,0 +1,179 @@
+package gvm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestListReleasesIsCachedForCacheTTL(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.CacheTTL = time.Minute
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Contains(t, r, "v0.14.1")
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestGetLatestReleaseURLChecksPrereleasesFromCache(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.CacheTTL = time.Minute
+	s.addRelease("v0.15.0-beta.1", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	_, _, err = v.GetLatestReleaseURL("~0.15.0")
+	assert.True(t, xerrors.Is(err, ErrOnlyPrereleasesMatch))
+
+	// the constraint is listed once, the check for pre-releases uses the cached releases
+	assert.Equal(t, 2, s.releasePages)
+}
+
+func TestListReleasesIsNotCachedByDefault(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	v.ListReleases("")
+	v.ListReleases("")
+
+	assert.Equal(t, 2, s.releasePages)
+}
+
+func TestBackgroundRefreshServesForegroundCallsFromCache(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v.StartBackgroundRefresh(ctx, "~0.14.0", time.Hour)
+
+	assert.Eventually(t, func() bool {
+		_, ok := v.cache.get("~0.14.0", 0)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	tag, _, err := v.GetLatestReleaseURL("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestBackgroundRefreshDoesNotCallGitHubWhenFrozen(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.Frozen = true
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v.StartBackgroundRefresh(ctx, "", 10*time.Millisecond)
+
+	time.Sleep(50 * time.Millisecond)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	assert.Equal(t, 0, s.releasePages)
+}
+
+func TestBackgroundRefreshRemovesCacheWhenStopped(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.StartBackgroundRefresh(ctx, "", time.Hour)
+
+	assert.Eventually(t, func() bool {
+		_, ok := v.cache.get("", 0)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	assert.Eventually(t, func() bool {
+		_, ok := v.cache.get("", 0)
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWarmCachesEachConstraintWithOneListing(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.2", "fake-service-linux")
+
+	err := v.Warm(context.Background(), "~0.14.0", "~0.13.0")
+	assert.NoError(t, err)
+
+	tag, _, err := v.GetLatestReleaseURL("~0.14.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+
+	tag, _, err = v.GetLatestReleaseURL("~0.13.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.13.2", tag)
+
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestWarmEntriesExpireAfterCacheTTL(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.CacheTTL = time.Millisecond
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	err := v.Warm(context.Background(), "")
+	assert.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+	v.ListReleases("")
+
+	assert.Equal(t, 2, s.releasePages)
+}
+
+func TestWarmReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	err := v.Warm(context.Background(), "abc")
+	assert.Error(t, err)
+}
+
+func TestWarmReturnsErrorWhenContextCancelled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := v.Warm(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestCompleteVersionsServedFromWarmCache(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	err := v.Warm(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, s.releasePages)
+
+	tags, err := v.CompleteVersions("0.14")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.14.2", "v0.14.1"}, tags)
+	assert.Equal(t, 1, s.releasePages)
+}
@@ -0,0 +1,93 @@
+package gvm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+// CommitMovedError is returned from VerifyReleaseCommit when the release tag
+// references a different commit to the one recorded at install
+type CommitMovedError struct {
+	Tag      string
+	Recorded string
+	Current  string
+}
+
+func (e *CommitMovedError) Error() string {
+	return fmt.Sprintf("Release %s has moved from commit %s to %s", e.Tag, e.Recorded, e.Current)
+}
+
+// VerifyReleaseCommit checks that the tag for an installed release still references
+// the commit which was recorded when the version was installed. When the tag has
+// been moved false is returned along with a CommitMovedError containing the new SHA
+func (v *VersionsImpl) VerifyReleaseCommit(tag string) (bool, error) {
+	if v.options.Frozen {
+		return false, ErrFrozen
+	}
+
+	m, err := v.metadataStore().Get(tag)
+	if err != nil {
+		return false, err
+	}
+
+	if m.Commit == "" {
+		return false, xerrors.Errorf("No commit was recorded when %s was installed", tag)
+	}
+
+	sha, err := v.resolveTagCommit(tag)
+	if err != nil {
+		return false, err
+	}
+
+	if sha != m.Commit {
+		return false, &CommitMovedError{Tag: tag, Recorded: m.Commit, Current: sha}
+	}
+
+	return true, nil
+}
+
+// installCommit returns the SHA referenced by the tag to record when the version is installed,
+// an empty string is returned when the tag does not exist in the repository, for example when
+// the asset was not published as a GitHub release
+func (v *VersionsImpl) installCommit(tag string) (string, error) {
+	sha, err := v.resolveTagCommit(tag)
+
+	var gerr *github.ErrorResponse
+	if xerrors.As(err, &gerr) && gerr.Response != nil && gerr.Response.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+
+	return sha, err
+}
+
+// maxTagDepth is the maximum number of annotated tags followed when resolving a tag to a commit
+const maxTagDepth = 5
+
+// resolveTagCommit returns the SHA of the commit referenced by the git tag, annotated
+// tags reference a tag object which is dereferenced to the commit it tags
+func (v *VersionsImpl) resolveTagCommit(tag string) (string, error) {
+	ref, _, err := v.client.Git.GetRef(context.Background(), v.options.Organization, v.options.Repo, "tags/"+tag)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to resolve tag %s: %w", tag, err)
+	}
+
+	obj := ref.Object
+	for i := 0; obj != nil && obj.GetType() == "tag" && i < maxTagDepth; i++ {
+		t, _, err := v.client.Git.GetTag(context.Background(), v.options.Organization, v.options.Repo, obj.GetSHA())
+		if err != nil {
+			return "", xerrors.Errorf("Unable to resolve annotated tag %s: %w", tag, err)
+		}
+
+		obj = t.Object
+	}
+
+	if obj == nil || obj.SHA == nil || obj.GetType() == "tag" {
+		return "", xerrors.Errorf("Tag %s does not reference a commit", tag)
+	}
+
+	return obj.GetSHA(), nil
+}
@@ -0,0 +1,104 @@
+package gvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestDownloadReleaseRecordsCommit(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.refs["v0.14.1"] = "abc123"
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	m, err := v.metadataStore().Get("v0.14.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", m.Commit)
+}
+
+func TestVerifyReleaseCommitReturnsTrueWhenUnchanged(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.refs["v0.14.1"] = "abc123"
+
+	v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+
+	ok, err := v.VerifyReleaseCommit("v0.14.1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyReleaseCommitReturnsFalseWhenRetagged(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.refs["v0.14.1"] = "abc123"
+
+	v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+
+	// move the tag to a new commit
+	s.refs["v0.14.1"] = "def456"
+
+	ok, err := v.VerifyReleaseCommit("v0.14.1")
+	assert.False(t, ok)
+
+	var moved *CommitMovedError
+	assert.True(t, xerrors.As(err, &moved))
+	assert.Equal(t, "abc123", moved.Recorded)
+	assert.Equal(t, "def456", moved.Current)
+}
+
+func TestVerifyReleaseCommitReturnsErrorWhenNoCommitRecorded(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	// the tag does not exist when the version is installed
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	s.refs["v0.14.1"] = "abc123"
+
+	ok, err := v.VerifyReleaseCommit("v0.14.1")
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	// the commit is not recorded by the verification
+	m, err := v.metadataStore().Get("v0.14.1")
+	assert.NoError(t, err)
+	assert.Empty(t, m.Commit)
+}
+
+func TestVerifyReleaseCommitReturnsErrorWhenNotInstalled(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.VerifyReleaseCommit("v0.14.1")
+	assert.Equal(t, ErrMetadataNotFound, err)
+}
+
+func TestDownloadReleaseRecordsCommitForAnnotatedTag(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.refs["v0.14.1"] = "tag789"
+	s.annotated["tag789"] = "abc123"
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	m, err := v.metadataStore().Get("v0.14.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", m.Commit)
+
+	// move the annotated tag to a new commit
+	s.annotated["tag789"] = "def456"
+
+	ok, err := v.VerifyReleaseCommit("v0.14.1")
+	assert.False(t, ok)
+
+	var moved *CommitMovedError
+	assert.True(t, xerrors.As(err, &moved))
+	assert.Equal(t, "abc123", moved.Recorded)
+	assert.Equal(t, "def456", moved.Current)
+}
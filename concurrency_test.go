@@ -0,0 +1,104 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVersionsIsSafeForConcurrentUse is intended to be run with the race detector
+// go test -race ./...
+func TestVersionsIsSafeForConcurrentUse(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.CacheTTL = time.Minute
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	err := v.LoadPresets([]byte(`{"lts": "~0.13.0"}`))
+	assert.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(4)
+
+		go func() {
+			defer wg.Done()
+
+			r, err := v.ListReleases("")
+			assert.NoError(t, err)
+			assert.Len(t, r, 3)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			tag, _, err := v.GetLatestReleaseURL("~0.14.0")
+			assert.NoError(t, err)
+			assert.Equal(t, "v0.14.2", tag)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			tag, _, err := v.GetLatestForPreset("lts")
+			assert.NoError(t, err)
+			assert.Equal(t, "v0.13.0", tag)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			err := v.LoadPresets([]byte(`{"lts": "~0.13.0"}`))
+			assert.NoError(t, err)
+
+			_, err = v.ListInstalledVersions("")
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestSetCurrentIsSafeForConcurrentUse(t *testing.T) {
+	tmp, v := setup(t)
+	tags := []string{"v0.14.1", "v0.14.2", "v0.14.3", "v0.14.4", "v0.14.5"}
+	for _, tag := range tags {
+		installExe(t, tmp, tag, tag)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		for _, tag := range tags {
+			wg.Add(1)
+
+			go func(tag string) {
+				defer wg.Done()
+
+				err := v.setCurrentIfHighest(tag)
+				assert.NoError(t, err)
+			}(tag)
+		}
+	}
+
+	wg.Wait()
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.5", c)
+
+	// no temporary files are left behind
+	for _, dir := range []string{tmp, path.Join(tmp, "bin")} {
+		files, err := ioutil.ReadDir(dir)
+		assert.NoError(t, err)
+
+		for _, f := range files {
+			assert.False(t, strings.Contains(f.Name(), ".tmp"), f.Name())
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package gvm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// currentFile is the name of the current version pointer file in ReleasesPath
+const currentFile = "current.json"
+
+// CurrentPointer is the format of the current version pointer file which is
+// written to ReleasesPath/current.json, e.g. { "current": "v1.2.3" }
+// The file can be read by external tooling to determine the active version
+type CurrentPointer struct {
+	Current string `json:"current"`
+}
+
+// SetCurrent sets the active version by placing its executable at ReleasesPath/bin using
+// ActivationMode and writing the current version pointer file
+// Returns ErrNotInstalled when the version is not installed
+func (v *VersionsImpl) SetCurrent(tag string) error {
+	v.currentMutex.Lock()
+	defer v.currentMutex.Unlock()
+
+	return v.setCurrent(tag)
+}
+
+// setCurrent is SetCurrent without holding currentMutex
+func (v *VersionsImpl) setCurrent(tag string) error {
+	if _, err := os.Stat(v.exePath(tag)); err != nil {
+		return ErrNotInstalled
+	}
+
+	err := v.activate(tag)
+	if err != nil {
+		return err
+	}
+
+	d, err := json.MarshalIndent(CurrentPointer{Current: tag}, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("Unable to encode current version: %w", err)
+	}
+
+	err = writeFileAtomic(path.Join(v.options.ReleasesPath, currentFile), d, 0644)
+	if err != nil {
+		return xerrors.Errorf("Unable to write current version: %w", err)
+	}
+
+	return nil
+}
+
+// setCurrentIfHighest sets the tag as the current version when it is the highest installed version,
+// the check and update are serialized so that parallel downloads can not set a lower version
+func (v *VersionsImpl) setCurrentIfHighest(tag string) error {
+	v.currentMutex.Lock()
+	defer v.currentMutex.Unlock()
+
+	installed, err := v.listInstalledVersions("")
+	if err != nil {
+		return err
+	}
+
+	keys := v.SortMapKeys(installed, true)
+	if len(keys) == 0 || keys[0] != tag {
+		return nil
+	}
+
+	return v.setCurrent(tag)
+}
+
+// InstallAndActivate installs the latest release matching the constraint, unless it is already
+// installed, and sets it as the current version. When the version is installed but cannot be
+// set as current the version remains installed and the tag, path and error are returned.
+// Returns ErrNoMatchingRelease when no release matches the constraint
+func (v *VersionsImpl) InstallAndActivate(constraint string) (string, string, error) {
+	tag, fp, err := v.installLatest(constraint)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = v.SetCurrent(tag)
+	if err != nil {
+		return tag, fp, xerrors.Errorf("Installed %s but unable to set it as the current version: %w", tag, err)
+	}
+
+	return tag, fp, nil
+}
+
+// GetCurrent returns the active version from the current version pointer file
+// Returns an empty string when no version has been set
+func (v *VersionsImpl) GetCurrent() (string, error) {
+	d, err := ioutil.ReadFile(path.Join(v.options.ReleasesPath, currentFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", xerrors.Errorf("Unable to read current version: %w", err)
+	}
+
+	c := CurrentPointer{}
+	err = json.Unmarshal(d, &c)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to parse current version: %w", err)
+	}
+
+	return c.Current, nil
+}
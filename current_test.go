@@ -0,0 +1,247 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestSetCurrentWritesPointerFile(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+
+	err := v.SetCurrent("v0.14.1")
+	assert.NoError(t, err)
+
+	d, err := ioutil.ReadFile(path.Join(tmp, "current.json"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"current": "v0.14.1"}`, string(d))
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", c)
+}
+
+func installExe(t *testing.T, tmp, tag, content string) string {
+	fp := path.Join(tmp, tag, "fake-service-linux")
+	os.MkdirAll(path.Dir(fp), os.ModePerm)
+	assert.NoError(t, ioutil.WriteFile(fp, []byte(content), 0755))
+
+	return fp
+}
+
+func TestSetCurrentSymlinksActiveExecutable(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ActivationMode = ActivationSymlink
+	installExe(t, tmp, "v0.14.1", "v0.14.1")
+	installExe(t, tmp, "v0.14.2", "v0.14.2")
+
+	for _, tag := range []string{"v0.14.1", "v0.14.2"} {
+		err := v.SetCurrent(tag)
+		assert.NoError(t, err)
+
+		fi, err := os.Lstat(path.Join(tmp, "bin", "fake-service"))
+		assert.NoError(t, err)
+		assert.True(t, fi.Mode()&os.ModeSymlink != 0)
+
+		d, err := ioutil.ReadFile(path.Join(tmp, "bin", "fake-service"))
+		assert.NoError(t, err)
+		assert.Equal(t, tag, string(d))
+	}
+}
+
+func TestSetCurrentCopiesActiveExecutable(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ActivationMode = ActivationCopy
+	fp := installExe(t, tmp, "v0.14.1", "binary")
+
+	err := v.SetCurrent("v0.14.1")
+	assert.NoError(t, err)
+
+	fi, err := os.Lstat(path.Join(tmp, "bin", "fake-service"))
+	assert.NoError(t, err)
+	assert.True(t, fi.Mode().IsRegular())
+	assert.Equal(t, os.FileMode(0755), fi.Mode().Perm())
+
+	src, _ := os.Stat(fp)
+	assert.False(t, os.SameFile(src, fi))
+
+	d, _ := ioutil.ReadFile(path.Join(tmp, "bin", "fake-service"))
+	assert.Equal(t, "binary", string(d))
+}
+
+func TestSetCurrentHardlinksActiveExecutable(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ActivationMode = ActivationHardlink
+	fp := installExe(t, tmp, "v0.14.1", "binary")
+
+	err := v.SetCurrent("v0.14.1")
+	assert.NoError(t, err)
+
+	fi, err := os.Lstat(path.Join(tmp, "bin", "fake-service"))
+	assert.NoError(t, err)
+
+	src, _ := os.Stat(fp)
+	assert.True(t, os.SameFile(src, fi))
+}
+
+func TestSetCurrentUsesStablePathForVersionedExecutables(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ActivationMode = ActivationCopy
+	v.options.ExeNameFunc = func(ver, goos, goarch string) string {
+		return "tool-" + ver
+	}
+
+	for _, tag := range []string{"v0.14.1", "v0.14.2"} {
+		fp := path.Join(tmp, tag, "tool-"+v.NormalizeTag(tag))
+		os.MkdirAll(path.Dir(fp), os.ModePerm)
+		assert.NoError(t, ioutil.WriteFile(fp, []byte(tag), 0755))
+
+		err := v.SetCurrent(tag)
+		assert.NoError(t, err)
+	}
+
+	files, err := ioutil.ReadDir(path.Join(tmp, "bin"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	d, _ := ioutil.ReadFile(path.Join(tmp, "bin", "fake-service"))
+	assert.Equal(t, "v0.14.2", string(d))
+}
+
+func TestSetCurrentUsesSavedBinaryNameForActiveExecutable(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.SavedBinaryName = "tool"
+	fp := path.Join(tmp, "v0.14.1", "tool")
+	os.MkdirAll(path.Dir(fp), os.ModePerm)
+	assert.NoError(t, ioutil.WriteFile(fp, []byte("binary"), 0755))
+
+	err := v.SetCurrent("v0.14.1")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, path.Join(tmp, "bin", "tool"))
+}
+
+func TestDefaultActivationModeCopiesOnWindows(t *testing.T) {
+	assert.Equal(t, ActivationSymlink, defaultActivationMode("linux"))
+	assert.Equal(t, ActivationCopy, defaultActivationMode("windows"))
+}
+
+func TestDownloadSetsCurrentWhenHighestInstalled(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.SetCurrentOnInstall = true
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	c, _ := v.GetCurrent()
+	assert.Equal(t, "v0.14.1", c)
+
+	_, err = v.DownloadRelease("v0.14.2", s.URL+"/download/v0.14.2/fake-service-linux")
+	assert.NoError(t, err)
+
+	c, _ = v.GetCurrent()
+	assert.Equal(t, "v0.14.2", c)
+}
+
+func TestDownloadDoesNotReplaceHigherCurrentVersion(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.SetCurrentOnInstall = true
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.2", s.URL+"/download/v0.14.2/fake-service-linux")
+	assert.NoError(t, err)
+
+	_, err = v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	c, _ := v.GetCurrent()
+	assert.Equal(t, "v0.14.2", c)
+}
+
+func TestDownloadDoesNotSetCurrentByDefault(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	c, _ := v.GetCurrent()
+	assert.Equal(t, "", c)
+}
+
+func TestSetCurrentReturnsErrorWhenNotInstalled(t *testing.T) {
+	_, v := setup(t)
+
+	err := v.SetCurrent("v0.14.1")
+	assert.Equal(t, ErrNotInstalled, err)
+}
+
+func TestGetCurrentReturnsEmptyWhenNotSet(t *testing.T) {
+	_, v := setup(t)
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "", c)
+}
+
+func TestGetCurrentReadsExternalPointerFile(t *testing.T) {
+	tmp, v := setup(t)
+
+	ioutil.WriteFile(path.Join(tmp, "current.json"), []byte(`{"current": "v0.12.2"}`), 0644)
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.12.2", c)
+}
+
+func TestInstallAndActivateInstallsAndSetsCurrent(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	tag, fp, err := v.InstallAndActivate("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.2", tag)
+	assert.Equal(t, path.Join(tmp, "v0.14.2", "fake-service-linux"), fp)
+	assert.FileExists(t, fp)
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.2", c)
+}
+
+func TestInstallAndActivateKeepsInstallWhenActivationFails(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	// a folder in place of the pointer file stops it being replaced
+	os.MkdirAll(path.Join(tmp, "current.json", "blocked"), os.ModePerm)
+
+	tag, fp, err := v.InstallAndActivate("")
+	assert.Error(t, err)
+
+	assert.Equal(t, "v0.14.2", tag)
+	assert.FileExists(t, fp)
+}
+
+func TestInstallAndActivateReturnsErrorWhenNoMatchingRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+
+	_, _, err := v.InstallAndActivate("~1.0.0")
+	assert.True(t, xerrors.Is(err, ErrNoMatchingRelease))
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "", c)
+}
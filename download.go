@@ -0,0 +1,778 @@
+package gvm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-getter"
+	"golang.org/x/xerrors"
+)
+
+// defaultConcurrency is the number of parallel downloads when Concurrency is not set
+const defaultConcurrency = 4
+
+// defaultMaxRedirects is the number of redirects followed when MaxRedirects is not set
+const defaultMaxRedirects = 10
+
+// defaultAllowedSchemes are the URL schemes assets can be downloaded from when AllowedSchemes is not set
+var defaultAllowedSchemes = []string{"https"}
+
+// defaultTempMaxAge is the age after which CleanupTemp removes a download folder when TempMaxAge is not set
+const defaultTempMaxAge = 24 * time.Hour
+
+// downloadDirPrefix is the prefix of the temporary folders used while downloading assets
+const downloadDirPrefix = ".download-"
+
+// ErrDisallowedScheme is returned when an asset URL uses a scheme which is not in AllowedSchemes
+var ErrDisallowedScheme = xerrors.New("URL scheme is not allowed")
+
+// ErrDisallowedHost is returned when an asset URL, or a URL it redirects to, does not match AllowedHosts
+var ErrDisallowedHost = xerrors.New("URL host is not allowed")
+
+// ErrInvalidSRI is returned when ChecksumFunc returns a malformed Subresource Integrity string
+var ErrInvalidSRI = xerrors.New("Invalid subresource integrity")
+
+// ErrTooManyRedirects is returned when a download exceeds MaxRedirects
+var ErrTooManyRedirects = xerrors.New("Too many redirects")
+
+// ErrRedirectLoop is returned when a download redirects to a URL it has already visited
+var ErrRedirectLoop = xerrors.New("Redirect loop detected")
+
+// ErrChecksumMismatch is returned when a downloaded asset does not match the checksum from ChecksumFunc
+var ErrChecksumMismatch = xerrors.New("Checksum does not match")
+
+// downloadAll downloads the given releases in parallel bounded by Concurrency
+// returning the paths and errors keyed by tag
+func (v *VersionsImpl) downloadAll(releases map[string]string) (map[string]string, map[string]error) {
+	tags := []string{}
+	for tag := range releases {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	results := make([]string, len(tags))
+	errs := parallel(len(tags), v.options.Concurrency, func(i int) error {
+		var err error
+
+		results[i], err = v.DownloadRelease(tags[i], releases[tags[i]])
+		return err
+	})
+
+	paths := map[string]string{}
+	failed := map[string]error{}
+
+	for i, tag := range tags {
+		if errs[i] != nil {
+			failed[tag] = errs[i]
+			continue
+		}
+
+		paths[tag] = results[i]
+	}
+
+	return paths, failed
+}
+
+// downloadDir creates a temporary folder in ReleasesPath which is used to hold
+// an asset while it is downloaded and verified
+func (v *VersionsImpl) downloadDir() (string, error) {
+	err := os.MkdirAll(v.options.ReleasesPath, os.ModePerm)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to create releases folder: %w", err)
+	}
+
+	dir, err := ioutil.TempDir(v.options.ReleasesPath, downloadDirPrefix)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to create temporary folder: %w", err)
+	}
+
+	return dir, nil
+}
+
+// CleanupTemp removes the temporary download folders left in ReleasesPath by interrupted
+// downloads and returns the number removed. Only folders which have not been modified for
+// TempMaxAge are removed so that the folders of downloads in progress are left in place
+func (v *VersionsImpl) CleanupTemp() (int, error) {
+	files, err := ioutil.ReadDir(v.options.ReleasesPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, xerrors.Errorf("Unable to list releases folder: %w", err)
+	}
+
+	removed := 0
+	for _, f := range files {
+		if !f.IsDir() || !strings.HasPrefix(f.Name(), downloadDirPrefix) {
+			continue
+		}
+
+		if time.Since(f.ModTime()) < v.options.TempMaxAge {
+			continue
+		}
+
+		err := os.RemoveAll(path.Join(v.options.ReleasesPath, f.Name()))
+		if err != nil {
+			return removed, xerrors.Errorf("Unable to remove temporary folder: %w", err)
+		}
+
+		removed++
+	}
+
+	return removed, nil
+}
+
+// fetchWithProxy downloads the asset through CacheProxyURL when set and ChecksumFunc
+// returns a checksum for the tag, falling back to fetching the asset directly when
+// the proxy fails or the proxied asset does not match the expected checksum
+func (v *VersionsImpl) fetchWithProxy(ctx context.Context, dir, tag, src string) (string, digests, error) {
+	algos := v.digestAlgos(tag)
+
+	if v.options.CacheProxyURL != "" && v.expectedChecksum(tag) != "" {
+		if pu, err := v.proxyURL(src); err == nil {
+			asset, d, err := v.fetch(ctx, dir, pu, algos)
+			if err == nil && v.verify(tag, asset, d) == nil {
+				return asset, d, nil
+			}
+
+			if asset != "" {
+				os.Remove(asset)
+			}
+		}
+	}
+
+	return v.fetch(ctx, dir, src, algos)
+}
+
+// proxyURL returns the URL for the asset at src on CacheProxyURL
+func (v *VersionsImpl) proxyURL(src string) (string, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", xerrors.Errorf("Invalid URL %s: %w", src, err)
+	}
+
+	pu, err := url.Parse(v.options.CacheProxyURL)
+	if err != nil {
+		return "", xerrors.Errorf("Invalid cache proxy URL %s: %w", v.options.CacheProxyURL, err)
+	}
+
+	pu.Path = path.Join(pu.Path, u.Host, u.Path)
+	pu.RawQuery = u.RawQuery
+
+	return pu.String(), nil
+}
+
+// fetch downloads the asset at src into the folder dir without uncompressing it
+// and returns the path of the downloaded file along with the digests for the given
+// algorithms, which are computed while the asset is downloaded.
+// HTTP and HTTPS assets are downloaded with the packages own client, all other
+// schemes are handled by go-getter and no digests are returned
+func (v *VersionsImpl) fetch(ctx context.Context, dir, src string, algos []string) (string, digests, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", nil, xerrors.Errorf("Invalid URL %s: %w", src, err)
+	}
+
+	err = v.checkScheme(u)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = v.checkHost(u)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fp := path.Join(dir, path.Base(u.Path))
+
+	// use the encoded URL so that names containing spaces or other reserved
+	// characters are correctly escaped
+	if u.Scheme == "http" || u.Scheme == "https" {
+		d, err := v.downloadFile(ctx, fp, u.String(), algos)
+		return fp, d, err
+	}
+
+	// stop go-getter uncompressing the archive so that it can be verified
+	q := u.Query()
+	q.Set("archive", "false")
+	u.RawQuery = q.Encode()
+
+	err = getter.GetFile(fp, u.String())
+	if err != nil {
+		return "", nil, xerrors.Errorf("Unable to download %s: %w", src, err)
+	}
+
+	return fp, nil, nil
+}
+
+// expectedChecksum returns the checksum from ChecksumFunc for the asset of the
+// given tag, returns an empty string when there is no checksum. Hex checksums are
+// returned in lower case, Subresource Integrity strings are returned unchanged
+func (v *VersionsImpl) expectedChecksum(tag string) string {
+	if v.options.ChecksumFunc == nil {
+		return ""
+	}
+
+	sum := strings.TrimSpace(v.options.ChecksumFunc(v.NormalizeTag(tag), v.options.GOOS, v.options.GOARCH))
+	if isSRI(sum) {
+		return sum
+	}
+
+	return strings.ToLower(sum)
+}
+
+// expectedSHA256 returns the hex encoded sha256 checksum of the asset for the given tag,
+// returns an empty string when there is no checksum or the checksum uses another algorithm
+func (v *VersionsImpl) expectedSHA256(tag string) string {
+	sum := v.expectedChecksum(tag)
+	if !isSRI(sum) {
+		return sum
+	}
+
+	algo, digest, err := parseSRI(sum)
+	if err != nil || algo != "sha256" {
+		return ""
+	}
+
+	return hex.EncodeToString(digest)
+}
+
+// digestAlgos returns the algorithms of the digests needed for the asset of the given tag,
+// sha256 is always included as it is recorded in the metadata and used by the content
+// addressed store
+func (v *VersionsImpl) digestAlgos(tag string) []string {
+	algos := []string{"sha256"}
+
+	if expected := v.expectedChecksum(tag); isSRI(expected) {
+		if algo, _, err := parseSRI(expected); err == nil && algo != "sha256" {
+			algos = append(algos, algo)
+		}
+	}
+
+	return algos
+}
+
+// verify checks the downloaded asset against the checksum returned by ChecksumFunc
+// which is either a hex encoded sha256 checksum or a Subresource Integrity string.
+// Digests computed during the download are used in place of reading the asset again
+func (v *VersionsImpl) verify(tag, asset string, d digests) error {
+	expected := v.expectedChecksum(tag)
+	if expected == "" {
+		return nil
+	}
+
+	if isSRI(expected) {
+		return verifySRI(asset, expected, d)
+	}
+
+	b, err := d.sum("sha256", asset)
+	if err != nil {
+		return xerrors.Errorf("Unable to read downloaded file: %w", err)
+	}
+
+	if sum := hex.EncodeToString(b); !strings.EqualFold(sum, expected) {
+		return xerrors.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, sum)
+	}
+
+	return nil
+}
+
+// sriHashes are the hash functions supported in Subresource Integrity strings
+var sriHashes = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// digests are the checksums of a downloaded file keyed by algorithm
+type digests map[string][]byte
+
+// sum returns the digest for the algorithm, hashing the file when the digest was
+// not computed during the download
+func (d digests) sum(algo, fp string) ([]byte, error) {
+	if b, ok := d[algo]; ok {
+		return b, nil
+	}
+
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sriHashes[algo]()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// isSRI returns true when the checksum is a Subresource Integrity string e.g. sha384-<base64>,
+// hex checksums never contain a dash
+func isSRI(sum string) bool {
+	return strings.Contains(sum, "-")
+}
+
+// parseSRI returns the algorithm and digest from a Subresource Integrity string
+func parseSRI(sri string) (string, []byte, error) {
+	parts := strings.SplitN(sri, "-", 2)
+	if len(parts) != 2 {
+		return "", nil, xerrors.Errorf("%w: %s", ErrInvalidSRI, sri)
+	}
+
+	algo := strings.ToLower(parts[0])
+	h, ok := sriHashes[algo]
+	if !ok {
+		return "", nil, xerrors.Errorf("%w: unsupported algorithm %s", ErrInvalidSRI, parts[0])
+	}
+
+	digest, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(digest) != h().Size() {
+		return "", nil, xerrors.Errorf("%w: invalid %s digest %s", ErrInvalidSRI, algo, parts[1])
+	}
+
+	return algo, digest, nil
+}
+
+// verifySRI checks the file against the Subresource Integrity string
+func verifySRI(fp, sri string, d digests) error {
+	algo, expected, err := parseSRI(sri)
+	if err != nil {
+		return err
+	}
+
+	sum, err := d.sum(algo, fp)
+	if err != nil {
+		return xerrors.Errorf("Unable to read downloaded file: %w", err)
+	}
+
+	if !bytes.Equal(sum, expected) {
+		return xerrors.Errorf("%w: expected %s, got %s-%s", ErrChecksumMismatch, sri, algo, base64.StdEncoding.EncodeToString(sum))
+	}
+
+	return nil
+}
+
+// unpack uncompresses the asset with the file name name into the folder dir, assets
+// which are not archives are moved into dir or linked when the asset is in the
+// content addressed store
+func (v *VersionsImpl) unpack(dir, name, asset string) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return xerrors.Errorf("Unable to create version folder: %w", err)
+	}
+
+	ext := archiveExt(name)
+	if ext == "" {
+		if v.options.ContentAddressedStore {
+			return linkBlob(asset, path.Join(dir, name))
+		}
+
+		err = os.Rename(asset, path.Join(dir, name))
+		if err != nil {
+			return xerrors.Errorf("Unable to move downloaded file: %w", err)
+		}
+
+		return nil
+	}
+
+	if v.options.StripComponents == 0 {
+		err = getter.Decompressors[ext].Decompress(dir, asset, true, 0)
+		if err != nil {
+			return xerrors.Errorf("Unable to uncompress archive: %w", err)
+		}
+
+		return nil
+	}
+
+	// uncompress to a temporary folder then move the files into dir
+	// without the leading path components
+	tmp, err := v.downloadDir()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	err = getter.Decompressors[ext].Decompress(tmp, asset, true, 0)
+	if err != nil {
+		return xerrors.Errorf("Unable to uncompress archive: %w", err)
+	}
+
+	return stripComponents(tmp, dir, v.options.StripComponents)
+}
+
+// stripComponents moves the files in src to dst removing n leading path components
+// from each file, like tar --strip-components files with n or fewer components are ignored
+func stripComponents(src, dst string, n int) error {
+	return filepath.Walk(src, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, fp)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) <= n {
+			return nil
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(path.Join(parts[n:]...)))
+
+		err = os.MkdirAll(filepath.Dir(target), os.ModePerm)
+		if err != nil {
+			return xerrors.Errorf("Unable to create folder: %w", err)
+		}
+
+		err = os.Rename(fp, target)
+		if err != nil {
+			return xerrors.Errorf("Unable to move uncompressed file: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// downloadFile downloads the given url to the file fp, the download is stopped when ctx is cancelled
+func (v *VersionsImpl) downloadFile(ctx context.Context, fp, src string, algos []string) (digests, error) {
+	f, err := os.Create(fp)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to create file: %w", err)
+	}
+	defer f.Close()
+
+	// tee the download through the hashes so the file does not need to be read again
+	hashes := map[string]hash.Hash{}
+	writers := []io.Writer{f}
+	for _, a := range algos {
+		h := sriHashes[a]()
+		hashes[a] = h
+		writers = append(writers, h)
+	}
+
+	err = v.stream(ctx, src, io.MultiWriter(writers...))
+	if err != nil {
+		return nil, err
+	}
+
+	d := digests{}
+	for a, h := range hashes {
+		d[a] = h.Sum(nil)
+	}
+
+	return d, nil
+}
+
+// DownloadReleaseToWriter streams the raw asset at the given URL to w without
+// uncompressing it or writing to disk. When the download fails after the transfer
+// has started a *PartialDownloadError is returned containing the number of bytes written.
+// Returns ErrFrozen when Frozen is set
+func (v *VersionsImpl) DownloadReleaseToWriter(ctx context.Context, src string, w io.Writer) error {
+	if v.options.Frozen {
+		return ErrFrozen
+	}
+
+	u, err := url.Parse(src)
+	if err != nil {
+		return xerrors.Errorf("Invalid URL %s: %w", src, err)
+	}
+
+	err = v.checkScheme(u)
+	if err != nil {
+		return err
+	}
+
+	err = v.checkHost(u)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return xerrors.Errorf("Unable to stream %s, only http and https URLs are supported", src)
+	}
+
+	return v.stream(ctx, u.String(), w)
+}
+
+// PartialDownloadError is returned when a download fails after data has been written
+type PartialDownloadError struct {
+	URL          string
+	BytesWritten int64
+	Err          error
+}
+
+func (e *PartialDownloadError) Error() string {
+	return fmt.Sprintf("Unable to download %s, failed after %d bytes: %s", e.URL, e.BytesWritten, e.Err)
+}
+
+func (e *PartialDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// stream writes the body of the given url to w
+func (v *VersionsImpl) stream(ctx context.Context, src string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return xerrors.Errorf("Unable to create request for %s: %w", src, err)
+	}
+
+	resp, err := v.httpClient().Do(req)
+	if err != nil {
+		return xerrors.Errorf("Unable to download %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("Unable to download %s, expected status 200, got %d", src, resp.StatusCode)
+	}
+
+	n, err := io.Copy(w, v.throttle.reader(ctx, resp.Body))
+	if err != nil {
+		return &PartialDownloadError{URL: src, BytesWritten: n, Err: err}
+	}
+
+	return nil
+}
+
+// httpClient returns the client used to download assets
+func (v *VersionsImpl) httpClient() *http.Client {
+	return &http.Client{CheckRedirect: v.checkRedirect, Timeout: v.options.DownloadTimeout}
+}
+
+// checkRedirect stops a download which exceeds MaxRedirects or revisits a URL
+func (v *VersionsImpl) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > v.options.MaxRedirects {
+		return xerrors.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, v.options.MaxRedirects)
+	}
+
+	for _, r := range via {
+		if r.URL.String() == req.URL.String() {
+			return xerrors.Errorf("%w: %s", ErrRedirectLoop, req.URL)
+		}
+	}
+
+	err := v.checkScheme(req.URL)
+	if err != nil {
+		return err
+	}
+
+	return v.checkHost(req.URL)
+}
+
+// checkScheme returns ErrDisallowedScheme when the scheme of the URL is not in AllowedSchemes
+func (v *VersionsImpl) checkScheme(u *url.URL) error {
+	for _, s := range v.options.AllowedSchemes {
+		if strings.EqualFold(s, u.Scheme) {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("%w: %s", ErrDisallowedScheme, u.Scheme)
+}
+
+// checkHost returns ErrDisallowedHost when AllowedHosts is set and the URL does not match
+// any of the entries. An entry is a host, optionally followed by a path, e.g. github.com/myorg/*,
+// in which case the URL path must be below the entry path
+func (v *VersionsImpl) checkHost(u *url.URL) error {
+	if len(v.options.AllowedHosts) == 0 {
+		return nil
+	}
+
+	for _, a := range v.options.AllowedHosts {
+		host := a
+		prefix := ""
+		if i := strings.Index(a, "/"); i >= 0 {
+			host = a[:i]
+			prefix = strings.TrimSuffix(strings.TrimSuffix(a[i:], "*"), "/") + "/"
+		}
+
+		if !strings.EqualFold(host, u.Host) && !strings.EqualFold(host, u.Hostname()) {
+			continue
+		}
+
+		if prefix == "" || strings.HasPrefix(u.Path, prefix) {
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("%w: %s", ErrDisallowedHost, u.Host+u.Path)
+}
+
+// executableMagic are the leading bytes of ELF, Mach-O and PE executables
+var executableMagic = [][]byte{
+	{0x7f, 'E', 'L', 'F'},
+	{0xfe, 0xed, 0xfa, 0xce},
+	{0xfe, 0xed, 0xfa, 0xcf},
+	{0xce, 0xfa, 0xed, 0xfe},
+	{0xcf, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+	{'M', 'Z'},
+}
+
+// selectLargestExecutable moves the largest executable file in dir to dir/name when
+// dir/name does not exist. A file is executable when it has an executable permission
+// bit set or starts with the magic number of an executable format
+func selectLargestExecutable(dir, name string) error {
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+
+	var largest string
+	var size int64
+
+	err := filepath.Walk(dir, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !fi.Mode().IsRegular() || fi.Size() <= size {
+			return nil
+		}
+
+		if fi.Mode()&0111 != 0 || hasExecutableMagic(fp) {
+			largest = fp
+			size = fi.Size()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("Unable to search for executable: %w", err)
+	}
+
+	if largest == "" {
+		return xerrors.Errorf("Unable to find an executable in %s", dir)
+	}
+
+	err = os.Rename(largest, target)
+	if err != nil {
+		return xerrors.Errorf("Unable to move executable: %w", err)
+	}
+
+	return nil
+}
+
+// keepFiles returns the paths relative to the version folder which are kept by CleanExtras,
+// the executable fp and the files returned from ExtraFilesFunc
+func (v *VersionsImpl) keepFiles(tag, fp string) []string {
+	keep := []string{}
+
+	if rel, err := filepath.Rel(v.versionDir(tag), fp); err == nil {
+		keep = append(keep, rel)
+	}
+
+	if v.options.ExtraFilesFunc != nil {
+		ver := v.NormalizeTag(tag)
+		keep = append(keep, v.options.ExtraFilesFunc(ver, v.options.GOOS, v.options.GOARCH)...)
+	}
+
+	return keep
+}
+
+// cleanExtras removes every file in dir which is not in keep, folders containing a kept
+// file are retained
+func cleanExtras(dir string, keep []string) error {
+	kept := map[string]bool{}
+	for _, k := range keep {
+		// mark every parent folder of the file so that it is not removed
+		for p := filepath.Clean(k); p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+			kept[p] = true
+		}
+	}
+
+	err := filepath.Walk(dir, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, fp)
+		if err != nil || rel == "." || kept[rel] {
+			return err
+		}
+
+		err = os.RemoveAll(fp)
+		if err != nil {
+			return err
+		}
+
+		if fi.IsDir() {
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return xerrors.Errorf("Unable to remove extra files: %w", err)
+	}
+
+	return nil
+}
+
+// hasExecutableMagic returns true when the file starts with the magic number of an executable
+func hasExecutableMagic(fp string) bool {
+	f, err := os.Open(fp)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(f, header)
+
+	for _, m := range executableMagic {
+		if n >= len(m) && bytes.Equal(header[:len(m)], m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// archiveExt returns the longest extension of the file name which has a
+// go-getter decompressor, returns an empty string when the file is not an archive
+func archiveExt(name string) string {
+	ext := ""
+	for k := range getter.Decompressors {
+		if strings.HasSuffix(name, "."+k) && len(k) > len(ext) {
+			ext = k
+		}
+	}
+
+	return ext
+}
+
+// trimArchiveExt removes any archive extension from the file name
+func trimArchiveExt(name string) string {
+	if ext := archiveExt(name); ext != "" {
+		return strings.TrimSuffix(name, "."+ext)
+	}
+
+	return name
+}
@@ -0,0 +1,852 @@
+package gvm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+// tarGz returns a gzipped tar archive containing the given executable files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	return tarGzModes(t, files, nil)
+}
+
+// tarGzModes returns a gzipped tar archive containing the given files, files
+// without a mode in modes are executable
+func tarGzModes(t *testing.T, files map[string]string, modes map[string]int64) []byte {
+	buf := bytes.NewBuffer(nil)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		mode, ok := modes[name]
+		if !ok {
+			mode = 0755
+		}
+
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content))})
+		assert.NoError(t, err)
+
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+
+	tw.Close()
+	gw.Close()
+
+	return buf.Bytes()
+}
+
+func TestDownloadUncompressesArchive(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux.tar.gz")
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{"fake-service-linux": "binary"})
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+	assert.NoFileExists(t, fp+".tar.gz")
+}
+
+func TestDownloadFollowsRedirects(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.mux.Handle("/redirect/fake-service-linux", http.RedirectHandler("/download/v0.14.1/fake-service-linux", http.StatusFound))
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/redirect/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+}
+
+func TestDownloadReturnsErrorWhenTooManyRedirects(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.MaxRedirects = 2
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.mux.Handle("/redirect/3/fake-service-linux", http.RedirectHandler("/redirect/2/fake-service-linux", http.StatusFound))
+	s.mux.Handle("/redirect/2/fake-service-linux", http.RedirectHandler("/redirect/1/fake-service-linux", http.StatusFound))
+	s.mux.Handle("/redirect/1/fake-service-linux", http.RedirectHandler("/download/v0.14.1/fake-service-linux", http.StatusFound))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/redirect/3/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrTooManyRedirects))
+}
+
+func TestDownloadReturnsErrorWhenRedirectLoop(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.mux.Handle("/redirect/a/fake-service-linux", http.RedirectHandler("/redirect/b/fake-service-linux", http.StatusFound))
+	s.mux.Handle("/redirect/b/fake-service-linux", http.RedirectHandler("/redirect/a/fake-service-linux", http.StatusFound))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/redirect/a/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrRedirectLoop))
+}
+
+func TestDownloadLatestNDownloadsTopReleases(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.Concurrency = 2
+	for _, tag := range []string{"v0.14.5", "v0.14.4", "v0.14.3", "v0.14.2", "v0.14.1"} {
+		s.addRelease(tag, "fake-service-linux")
+	}
+
+	// v0.14.4 is already installed so should not be downloaded
+	os.MkdirAll(path.Join(tmp, "v0.14.4"), os.ModePerm)
+	ioutil.WriteFile(path.Join(tmp, "v0.14.4", "fake-service-linux"), []byte("installed"), os.ModePerm)
+
+	paths, errs := v.DownloadLatestN("", 3)
+	assert.Len(t, errs, 0)
+
+	assert.Len(t, paths, 3)
+	assert.Contains(t, paths, "v0.14.5")
+	assert.Contains(t, paths, "v0.14.4")
+	assert.Contains(t, paths, "v0.14.3")
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.2"))
+
+	d, _ := ioutil.ReadFile(paths["v0.14.4"])
+	assert.Equal(t, "installed", string(d))
+}
+
+func TestDownloadLatestNReturnsErrorsByTag(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	delete(s.assets, "/download/v0.14.2/fake-service-linux")
+
+	paths, errs := v.DownloadLatestN("", 2)
+
+	assert.Contains(t, paths, "v0.14.1")
+	assert.Contains(t, errs, "v0.14.2")
+}
+
+func TestDownloadLatestNDownloadsNothingWhenZero(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	paths, errs := v.DownloadLatestN("", 0)
+	assert.Len(t, errs, 0)
+	assert.Len(t, paths, 0)
+
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.2"))
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+}
+
+func TestDownloadLatestNReturnsErrorWhenNegative(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	paths, errs := v.DownloadLatestN("", -1)
+	assert.Error(t, errs[""])
+	assert.Len(t, paths, 0)
+
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.2"))
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+}
+
+func TestDownloadReturnsErrorWhenSchemeNotAllowed(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AllowedSchemes = []string{"https"}
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrDisallowedScheme))
+
+	_, err = v.DownloadRelease("v0.14.1", "file:///etc/passwd")
+	assert.True(t, xerrors.Is(err, ErrDisallowedScheme))
+}
+
+func TestDownloadAllowsURLMatchingAllowedHosts(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AllowedHosts = []string{"github.com/nicholasjackson/*", "127.0.0.1/download/*"}
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+}
+
+func TestDownloadReturnsErrorWhenHostNotAllowed(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AllowedHosts = []string{"github.com"}
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrDisallowedHost))
+}
+
+func TestDownloadReturnsErrorWhenPathNotAllowed(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AllowedHosts = []string{"127.0.0.1/other/*"}
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrDisallowedHost))
+}
+
+func TestDownloadReturnsErrorWhenRedirectHostNotAllowed(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AllowedHosts = []string{"127.0.0.1/download/*"}
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	u, _ := url.Parse(s.URL)
+	target := fmt.Sprintf("http://localhost:%s/download/v0.14.1/fake-service-linux", u.Port())
+	s.mux.Handle("/download/v0.14.1/moved", http.RedirectHandler(target, http.StatusFound))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/moved")
+	assert.True(t, xerrors.Is(err, ErrDisallowedHost))
+}
+
+func TestNewDefaultsAllowedSchemesToHTTPS(t *testing.T) {
+	v := New(Options{}).(*VersionsImpl)
+
+	assert.Equal(t, []string{"https"}, v.options.AllowedSchemes)
+}
+
+func TestDownloadSavesBinaryWithSavedBinaryName(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.SavedBinaryName = "tool"
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{"fake-service-linux": "binary"})
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "v0.14.1", "tool"), fp)
+	assert.FileExists(t, fp)
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "fake-service-linux"))
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, r["v0.14.1"])
+
+	tag, ip, err := v.GetInstalledVersion("")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, fp, ip)
+}
+
+func TestSavedBinaryNameFindsVersionsInstalledWithoutIt(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(tmp, "v0.14.1", "fake-service-linux"), fp)
+
+	v.options.SavedBinaryName = "tool"
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, r["v0.14.1"])
+
+	tag, ip, err := v.GetInstalledVersion("")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, fp, ip)
+}
+
+func TestDownloadReleaseToWriterStreamsRawAsset(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	archive := tarGz(t, map[string]string{"fake-service-linux": "binary"})
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = archive
+
+	buf := &bytes.Buffer{}
+	err := v.DownloadReleaseToWriter(context.Background(), s.URL+"/download/v0.14.1/fake-service-linux.tar.gz", buf)
+	assert.NoError(t, err)
+
+	assert.Equal(t, archive, buf.Bytes())
+
+	// nothing is written to the releases folder
+	files, _ := ioutil.ReadDir(tmp)
+	assert.Len(t, files, 0)
+}
+
+func TestDownloadReleaseToWriterReturnsErrorWhenHostNotAllowed(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AllowedHosts = []string{"github.com"}
+	s.addRelease("v0.14.1", "fake-service-linux")
+	buf := &bytes.Buffer{}
+
+	err := v.DownloadReleaseToWriter(context.Background(), s.URL+"/download/v0.14.1/fake-service-linux", buf)
+	assert.True(t, xerrors.Is(err, ErrDisallowedHost))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestDownloadReleaseToWriterRespectsContextCancellation(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := v.DownloadReleaseToWriter(ctx, s.URL+"/download/v0.14.1/fake-service-linux", &bytes.Buffer{})
+	assert.True(t, xerrors.Is(err, context.Canceled))
+}
+
+func TestDownloadReleaseToWriterReturnsErrorWhenDownloadTimesOut(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.DownloadTimeout = 50 * time.Millisecond
+
+	s.mux.HandleFunc("/hang/", func(rw http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	start := time.Now()
+	err := v.DownloadReleaseToWriter(context.Background(), s.URL+"/hang/fake-service-linux", &bytes.Buffer{})
+
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestDownloadReleaseToWriterReturnsErrorWhenFrozen(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.Frozen = true
+	s.addRelease("v0.14.1", "fake-service-linux")
+	buf := &bytes.Buffer{}
+
+	err := v.DownloadReleaseToWriter(context.Background(), s.URL+"/download/v0.14.1/fake-service-linux", buf)
+	assert.True(t, xerrors.Is(err, ErrFrozen))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestFetchRespectsContextCancellation(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := v.fetch(ctx, tmp, s.URL+"/download/v0.14.1/fake-service-linux", nil)
+	assert.True(t, xerrors.Is(err, context.Canceled))
+}
+
+func TestDownloadReleaseToWriterReportsBytesWrittenOnError(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.mux.HandleFunc("/truncated/fake-service-linux", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", "100")
+		rw.Write([]byte("partial"))
+	})
+
+	err := v.DownloadReleaseToWriter(context.Background(), s.URL+"/truncated/fake-service-linux", &bytes.Buffer{})
+
+	var pe *PartialDownloadError
+	assert.True(t, xerrors.As(err, &pe))
+	assert.Equal(t, int64(7), pe.BytesWritten)
+}
+
+func TestDownloadReleaseWithSpaceInAssetName(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.AssetNameFunc = func(ver, goos, goarch string) string { return "fake%20service-linux" }
+	v.options.ExeNameFunc = func(ver, goos, goarch string) string { return "fake service-linux" }
+	s.addRelease("v0.14.1", "fake service-linux")
+
+	tag, url, err := v.GetLatestReleaseURL("")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+
+	fp, err := v.DownloadRelease(tag, url)
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+}
+
+func TestCleanupTempRemovesInterruptedDownloads(t *testing.T) {
+	tmp, v := setup(t)
+	os.MkdirAll(path.Join(tmp, ".download-123"), os.ModePerm)
+	os.MkdirAll(path.Join(tmp, ".download-456"), os.ModePerm)
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+
+	old := time.Now().Add(-2 * defaultTempMaxAge)
+	os.Chtimes(path.Join(tmp, ".download-123"), old, old)
+	os.Chtimes(path.Join(tmp, ".download-456"), old, old)
+
+	n, err := v.CleanupTemp()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, n)
+	assert.NoDirExists(t, path.Join(tmp, ".download-123"))
+	assert.NoDirExists(t, path.Join(tmp, ".download-456"))
+	assert.DirExists(t, path.Join(tmp, "v0.14.1"))
+}
+
+func TestCleanupTempDoesNotRemoveRecentDownloads(t *testing.T) {
+	_, v := setup(t)
+
+	dir, err := v.downloadDir()
+	assert.NoError(t, err)
+
+	n, err := v.CleanupTemp()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, n)
+	assert.DirExists(t, dir)
+}
+
+func TestCleanupTempReturnsZeroWhenReleasesPathMissing(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ReleasesPath = path.Join(tmp, "missing")
+
+	n, err := v.CleanupTemp()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestDownloadStripsLeadingComponents(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.StripComponents = 1
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{
+		"fake-service-0.14.1/fake-service-linux": "binary",
+		"fake-service-0.14.1/docs/README.md":     "readme",
+		"LICENSE":                                "license",
+	})
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "v0.14.1", "fake-service-linux"), fp)
+	assert.FileExists(t, fp)
+	assert.FileExists(t, path.Join(tmp, "v0.14.1", "docs", "README.md"))
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "LICENSE"))
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1", "fake-service-0.14.1"))
+}
+
+func TestDownloadCleansExtraFiles(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.CleanExtras = true
+	s.addRelease("v0.14.1", "fake-service-linux.tar.gz")
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{
+		"fake-service-linux": "binary",
+		"docs/README.md":     "readme",
+		"LICENSE":            "license",
+		"other-tool":         "other",
+	})
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "LICENSE"))
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "other-tool"))
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1", "docs"))
+}
+
+func TestDownloadCleanExtrasKeepsExtraFiles(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.CleanExtras = true
+	v.options.ExtraFilesFunc = func(ver, goos, goarch string) []string {
+		return []string{"docs/README.md"}
+	}
+	v.options.ExeNameFunc = func(ver, goos, goarch string) string {
+		return "bin/fake-service"
+	}
+	s.addRelease("v0.14.1", "fake-service-linux.tar.gz")
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{
+		"bin/fake-service": "binary",
+		"bin/other-tool":   "other",
+		"docs/README.md":   "readme",
+		"docs/CHANGELOG":   "changelog",
+		"LICENSE":          "license",
+	})
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+	assert.FileExists(t, path.Join(tmp, "v0.14.1", "docs", "README.md"))
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "docs", "CHANGELOG"))
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "bin", "other-tool"))
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "LICENSE"))
+}
+
+func TestDownloadKeepsExtraFilesByDefault(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux.tar.gz")
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{
+		"fake-service-linux": "binary",
+		"LICENSE":            "license",
+	})
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, path.Join(tmp, "v0.14.1", "LICENSE"))
+}
+
+func TestInstallFromURLInstallsAssetAsTag(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.assets["/download/build-1234/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{"fake-service-linux": "binary"})
+	s.refs["v0.15.0-dev"] = "abc123"
+
+	fp, err := v.InstallFromURL("v0.15.0-dev", s.URL+"/download/build-1234/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "v0.15.0-dev", "fake-service-linux"), fp)
+	assert.FileExists(t, fp)
+
+	m, err := v.metadataStore().Get("v0.15.0-dev")
+	assert.NoError(t, err)
+	assert.Equal(t, s.URL+"/download/build-1234/fake-service-linux.tar.gz", m.URL)
+	// GitHub is not called to resolve the commit
+	assert.Empty(t, m.Commit)
+
+	installed, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Contains(t, installed, "v0.15.0-dev")
+}
+
+func TestInstallFromURLVerifiesChecksum(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.assets["/download/build-1234/fake-service-linux"] = []byte("binary")
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return "0000000000000000000000000000000000000000000000000000000000000000"
+	}
+
+	_, err := v.InstallFromURL("v0.15.0", s.URL+"/download/build-1234/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrChecksumMismatch))
+}
+
+func TestInstallFromURLReturnsErrorWhenTagNotSemver(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.assets["/download/build-1234/fake-service-linux"] = []byte("binary")
+
+	_, err := v.InstallFromURL("latest", s.URL+"/download/build-1234/fake-service-linux")
+	assert.Error(t, err)
+}
+
+func TestDownloadUsesCacheProxy(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	u, _ := url.Parse(s.URL)
+	requested := ""
+	s.mux.HandleFunc("/proxy/", func(rw http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		rw.Write([]byte("fake-service-linux"))
+	})
+	v.options.CacheProxyURL = s.URL + "/proxy"
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return "59f2d4f597005c9030cd4a90e9e622356561e3751d473f247ec9b151e1ab5393"
+	}
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+	assert.Equal(t, "/proxy/"+u.Host+"/download/v0.14.1/fake-service-linux", requested)
+}
+
+func TestDownloadDoesNotUseCacheProxyWithoutChecksum(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	requested := false
+	s.mux.HandleFunc("/proxy/", func(rw http.ResponseWriter, r *http.Request) {
+		requested = true
+		rw.Write([]byte("tampered"))
+	})
+	v.options.CacheProxyURL = s.URL + "/proxy"
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	d, _ := ioutil.ReadFile(fp)
+	assert.Equal(t, "fake-service-linux", string(d))
+	assert.False(t, requested)
+}
+
+func TestDownloadFallsBackWhenCacheProxyFails(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.CacheProxyURL = s.URL + "/missing"
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.FileExists(t, fp)
+}
+
+func TestDownloadFallsBackWhenCacheProxyReturnsBadAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.mux.HandleFunc("/proxy/", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("poisoned"))
+	})
+	v.options.CacheProxyURL = s.URL + "/proxy"
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return "59f2d4f597005c9030cd4a90e9e622356561e3751d473f247ec9b151e1ab5393"
+	}
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	d, _ := ioutil.ReadFile(fp)
+	assert.Equal(t, "fake-service-linux", string(d))
+}
+
+func TestDownloadReturnsErrorWhenProbedVersionDoesNotMatch(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.VersionProbeFunc = func(path string) (string, error) {
+		return "0.14.1-dev", nil
+	}
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrVersionMismatch))
+
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+}
+
+func TestDownloadSucceedsWhenProbedVersionMatches(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	probed := ""
+	v.options.VersionProbeFunc = func(path string) (string, error) {
+		probed = path
+		return "0.14.1", nil
+	}
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.Equal(t, fp, probed)
+}
+
+func TestDownloadSelectsLargestExecutable(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.SelectLargestExecutable = true
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGzModes(t,
+		map[string]string{
+			"bin/fake-service-v0.14.1": "the real binary",
+			"bin/helper":               "helper",
+			"README.md":                "a readme which is larger than the binary",
+			"lib/plugin.so":            "\x7fELF",
+		},
+		map[string]int64{"README.md": 0644, "lib/plugin.so": 0644},
+	)
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "v0.14.1", "fake-service-linux"), fp)
+
+	d, _ := ioutil.ReadFile(fp)
+	assert.Equal(t, "the real binary", string(d))
+}
+
+func TestDownloadSelectsLargestExecutableByMagicNumber(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.SelectLargestExecutable = true
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGzModes(t,
+		map[string]string{
+			"fake-service": "\x7fELF binary",
+			"README.md":    "a readme which is larger than the binary",
+		},
+		map[string]int64{"fake-service": 0644, "README.md": 0644},
+	)
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux.tar.gz")
+	assert.NoError(t, err)
+
+	d, _ := ioutil.ReadFile(fp)
+	assert.Equal(t, "\x7fELF binary", string(d))
+}
+
+func TestResumeBatchDownloadsOnlyMissingVersions(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.0", "fake-service-linux")
+
+	installed, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	corrupted, err := v.DownloadRelease("v0.14.0", s.URL+"/download/v0.14.0/fake-service-linux")
+	assert.NoError(t, err)
+	ioutil.WriteFile(corrupted, []byte("corrupted"), 0755)
+
+	// the healthy version is not downloaded again
+	delete(s.assets, "/download/v0.14.1/fake-service-linux")
+
+	paths, errs := v.ResumeBatch([]string{"v0.14.0", "v0.14.1", "v0.14.2", "v0.13.0"})
+
+	assert.Len(t, paths, 3)
+	assert.Equal(t, installed, paths["v0.14.1"])
+	assert.FileExists(t, paths["v0.14.2"])
+	assert.NoError(t, v.VerifyIntegrity("v0.14.0"))
+
+	assert.Len(t, errs, 1)
+	assert.True(t, xerrors.Is(errs["v0.13.0"], ErrNoMatchingRelease))
+}
+
+func TestResumeBatchDoesNotListReleasesWhenAllInstalled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	paths, errs := v.ResumeBatch([]string{"v0.14.1"})
+
+	assert.Len(t, paths, 1)
+	assert.Empty(t, errs)
+	assert.Equal(t, 0, s.releasePages)
+}
+
+func TestRelativePathsReturnsPathsRelativeToReleasesPath(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.RelativePaths = true
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join("v0.14.1", "fake-service-linux"), fp)
+	assert.FileExists(t, path.Join(tmp, fp))
+
+	installed, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, installed["v0.14.1"])
+
+	_, ip, err := v.GetInstalledVersion("")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, ip)
+
+	tag, err := v.WhichInstalled(path.Join(tmp, fp))
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+}
+
+func TestPathsAreJoinedToReleasesPathByDefault(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "v0.14.1", "fake-service-linux"), fp)
+}
+
+func sri(algo string, sum []byte) string {
+	return algo + "-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+func TestDownloadVerifiesSubresourceIntegrity(t *testing.T) {
+	sha256Sum := sha256.Sum256([]byte("fake-service-linux"))
+	sha384Sum := sha512.Sum384([]byte("fake-service-linux"))
+	sha512Sum := sha512.Sum512([]byte("fake-service-linux"))
+
+	for _, integrity := range []string{sri("sha256", sha256Sum[:]), sri("sha384", sha384Sum[:]), sri("sha512", sha512Sum[:])} {
+		_, v, s := setupStub(t)
+		s.addRelease("v0.14.1", "fake-service-linux")
+		v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+			return integrity
+		}
+
+		fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+		assert.NoError(t, err, integrity)
+		assert.FileExists(t, fp)
+	}
+}
+
+func TestDownloadReturnsErrorWhenSubresourceIntegrityDoesNotMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	sum := sha512.Sum384([]byte("other"))
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return sri("sha384", sum[:])
+	}
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrChecksumMismatch))
+}
+
+func TestDownloadReturnsErrorWhenSubresourceIntegrityIsMalformed(t *testing.T) {
+	sum := sha512.Sum384([]byte("fake-service-linux"))
+
+	for _, integrity := range []string{"sha384-not base64!", "md5-" + base64.StdEncoding.EncodeToString(sum[:16]), sri("sha512", sum[:])} {
+		_, v, s := setupStub(t)
+		s.addRelease("v0.14.1", "fake-service-linux")
+		v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+			return integrity
+		}
+
+		_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+		assert.True(t, xerrors.Is(err, ErrInvalidSRI), integrity)
+	}
+}
+
+func TestFetchComputesDigestsWhileDownloading(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	sum := sha512.Sum384([]byte("fake-service-linux"))
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return sri("sha384", sum[:])
+	}
+
+	fp, d, err := v.fetch(context.Background(), tmp, s.URL+"/download/v0.14.1/fake-service-linux", v.digestAlgos("v0.14.1"))
+	assert.NoError(t, err)
+
+	sha256Sum := sha256.Sum256([]byte("fake-service-linux"))
+	assert.Equal(t, sha256Sum[:], d["sha256"])
+	assert.Equal(t, sum[:], d["sha384"])
+
+	// the file is not read again when verifying
+	os.Remove(fp)
+	assert.NoError(t, v.verify("v0.14.1", fp, d))
+}
+
+func TestDownloadRecordsChecksumComputedWhileDownloading(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	sum := sha256.Sum256([]byte("fake-service-linux"))
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return hex.EncodeToString(sum[:])
+	}
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	m, err := v.metadataStore().Get("v0.14.1")
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), m.SHA256)
+}
+
+func BenchmarkDownloadReleaseWithChecksum(b *testing.B) {
+	_, v, s := setupStub(b)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	data := bytes.Repeat([]byte("fake-service"), 1<<20)
+	s.assets["/download/v0.14.1/fake-service-linux"] = data
+
+	sum := sha256.Sum256(data)
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return hex.EncodeToString(sum[:])
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
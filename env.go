@@ -0,0 +1,43 @@
+package gvm
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envNameInvalid matches the characters which are not allowed in environment variable names
+var envNameInvalid = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// GenerateEnv returns the environment variables which select the installed version for
+// the tag, <REPO>_HOME is set to the folder containing the executable and PATH has the
+// folder prepended to the PATH of the current process. e.g. for the repo fake-service
+// FAKE_SERVICE_HOME=/releases/v1.2.3 and PATH=/releases/v1.2.3:/usr/bin
+// Returns ErrNotInstalled when the version is not installed
+func (v *VersionsImpl) GenerateEnv(tag string) (map[string]string, error) {
+	fp := v.exePath(tag)
+	if _, err := os.Stat(fp); err != nil {
+		return nil, ErrNotInstalled
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(fp))
+	if err != nil {
+		return nil, err
+	}
+
+	p := dir
+	if existing := os.Getenv("PATH"); existing != "" {
+		p = dir + string(os.PathListSeparator) + existing
+	}
+
+	return map[string]string{
+		v.homeEnvName(): dir,
+		"PATH":          p,
+	}, nil
+}
+
+// homeEnvName returns the name of the home environment variable for the repo e.g. FAKE_SERVICE_HOME
+func (v *VersionsImpl) homeEnvName() string {
+	return envNameInvalid.ReplaceAllString(strings.ToUpper(v.options.Repo), "_") + "_HOME"
+}
@@ -0,0 +1,38 @@
+package gvm
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateEnvReturnsPathForVersion(t *testing.T) {
+	tmp, v := setup(t)
+	original := os.Getenv("PATH")
+	defer os.Setenv("PATH", original)
+	os.Setenv("PATH", "/usr/bin")
+
+	for _, tag := range []string{"v0.14.1", "v0.14.2"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	env, err := v.GenerateEnv("v0.14.1")
+	assert.NoError(t, err)
+
+	dir, _ := filepath.Abs(path.Join(tmp, "v0.14.1"))
+	assert.Equal(t, dir, env["FAKE_SERVICE_HOME"])
+	assert.Equal(t, dir, strings.Split(env["PATH"], string(os.PathListSeparator))[0])
+	assert.Equal(t, dir+string(os.PathListSeparator)+"/usr/bin", env["PATH"])
+}
+
+func TestGenerateEnvReturnsErrorWhenNotInstalled(t *testing.T) {
+	_, v := setup(t)
+
+	_, err := v.GenerateEnv("v0.14.1")
+	assert.Equal(t, ErrNotInstalled, err)
+}
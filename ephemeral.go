@@ -0,0 +1,54 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// InstallEphemeral downloads and uncompresses the release into a new temporary folder outside
+// of ReleasesPath, returning the path of the executable and a function which removes the folder.
+// The version is not recorded as installed, the cleanup function can be called more than once
+func (v *VersionsImpl) InstallEphemeral(tag, url string) (string, func() error, error) {
+	dir, err := ioutil.TempDir("", "gvm-ephemeral-")
+	if err != nil {
+		return "", nil, xerrors.Errorf("Unable to create temporary folder: %w", err)
+	}
+
+	var once sync.Once
+	var cerr error
+	cleanup := func() error {
+		once.Do(func() {
+			cerr = os.RemoveAll(dir)
+		})
+
+		return cerr
+	}
+
+	fp, err := v.scratch(dir).downloadRelease(tag, url, false)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return fp, cleanup, nil
+}
+
+// scratch returns a copy of the Versions which installs to dir without affecting the
+// versions, metadata or current version in ReleasesPath
+func (v *VersionsImpl) scratch(dir string) *VersionsImpl {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	o := v.options
+	o.ReleasesPath = dir
+	o.Layout = LayoutFlat
+	o.MetadataStore = nil
+	o.ContentAddressedStore = false
+	o.SetCurrentOnInstall = false
+	o.RelativePaths = false
+
+	return &VersionsImpl{options: o, client: v.client, nativeArch: v.nativeArch, throttle: v.throttle}
+}
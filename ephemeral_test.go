@@ -0,0 +1,58 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallEphemeralInstallsOutsideReleasesPath(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.SetCurrentOnInstall = true
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, cleanup, err := v.InstallEphemeral("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+	defer cleanup()
+
+	assert.FileExists(t, fp)
+	assert.False(t, strings.HasPrefix(fp, tmp))
+	assert.Equal(t, "fake-service-linux", filepath.Base(fp))
+
+	installed, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Empty(t, installed)
+
+	c, _ := v.GetCurrent()
+	assert.Empty(t, c)
+}
+
+func TestInstallEphemeralCleanupRemovesInstall(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, cleanup, err := v.InstallEphemeral("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	d, err := ioutil.ReadFile(fp)
+	assert.NoError(t, err)
+	assert.Equal(t, "fake-service-linux", string(d))
+
+	assert.NoError(t, cleanup())
+	assert.NoFileExists(t, fp)
+	assert.NoDirExists(t, filepath.Dir(filepath.Dir(fp)))
+
+	// cleanup can be called again
+	assert.NoError(t, cleanup())
+}
+
+func TestInstallEphemeralReturnsErrorWhenDownloadFails(t *testing.T) {
+	_, v, s := setupStub(t)
+
+	_, cleanup, err := v.InstallEphemeral("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.Error(t, err)
+	assert.Nil(t, cleanup)
+}
@@ -0,0 +1,300 @@
+package gvm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+const (
+	skipOutOfRange      = "does not match the constraint"
+	skipNoAssets        = "has no assets"
+	skipNoMatchingAsset = "has no asset for the platform"
+	skipInvalidVersion  = "is not a semantic version"
+	skipPrerelease      = "is a pre-release and IncludePrerelease is not set"
+)
+
+// ReleaseDecision records whether a release was included when filtering releases with a
+// constraint, or the reason the release was skipped
+type ReleaseDecision struct {
+	Tag string
+	// URL is the download URL of the asset for the platform when the release is included
+	URL      string
+	Included bool
+	// Reason is a human readable reason the release was skipped, empty when the release is included
+	Reason string
+}
+
+// releaseDecision is the result of filtering a GitHub release with a constraint
+type releaseDecision struct {
+	tag string
+	// url is the download URL when the release is selected
+	url string
+	// skip is the reason the release was not selected, empty when the release is selected
+	skip string
+}
+
+// decide filters the GitHub release with the constraint and selects the asset for the platform,
+// returns an error when the constraint is invalid or the release has no assets and
+// NoAssetBehavior is NoAssetError
+func (v *VersionsImpl) decide(g *github.RepositoryRelease, constraint string) (releaseDecision, error) {
+	d := releaseDecision{tag: g.GetTagName()}
+
+	if constraint != "" {
+		if _, err := semver.NewConstraint(constraint); err != nil {
+			return d, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+		}
+	}
+
+	// tags which are not semantic versions, e.g. nightly, are never selected
+	if _, err := v.parseVersion(d.tag); err != nil {
+		d.skip = skipInvalidVersion
+		return d, nil
+	}
+
+	// does this tag match the provided semver
+	if constraint != "" {
+		valid, err := v.InRange(d.tag, constraint)
+		if err != nil {
+			return d, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+		}
+
+		if !valid {
+			d.skip = skipOutOfRange
+			return d, nil
+		}
+	}
+
+	if len(g.Assets) == 0 {
+		switch v.options.NoAssetBehavior {
+		case NoAssetError:
+			return d, xerrors.Errorf("%w: %s", ErrNoAssets, d.tag)
+		case NoAssetSource:
+			d.url = v.sourceURL(d.tag)
+		default:
+			d.skip = skipNoAssets
+		}
+
+		return d, nil
+	}
+
+	// check there is an asset with the given filename
+	a := v.findAsset(d.tag, g.Assets)
+	if a == nil {
+		d.skip = skipNoMatchingAsset
+		return d, nil
+	}
+
+	d.url = a.GetBrowserDownloadURL()
+
+	return d, nil
+}
+
+// Explain returns a human readable trace of how the release for the constraint is selected,
+// listing the releases fetched, those matching the constraint, those with an asset for the
+// platform and the selected release
+func (v *VersionsImpl) Explain(constraint string) (string, error) {
+	ctx := context.Background()
+	constraint = v.constraint(constraint)
+
+	sb := &strings.Builder{}
+	if constraint == "" {
+		fmt.Fprintln(sb, "Constraint: any version")
+	} else {
+		fmt.Fprintf(sb, "Constraint: %s\n", constraint)
+	}
+
+	if v.options.Frozen || v.options.UseTags {
+		source := "installed versions, Frozen is set"
+		if !v.options.Frozen {
+			source = "tags, UseTags is set"
+		}
+
+		fmt.Fprintf(sb, "Releases are resolved from %s\n", source)
+
+		tags, err := v.ListReleases(constraint)
+		if err != nil {
+			return "", err
+		}
+
+		v.explainSelected(ctx, sb, constraint, v.SortMapKeys(tags, true))
+
+		return sb.String(), nil
+	}
+
+	gr, err := v.listGitHubReleasesContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(sb, "Fetched %d releases from %s/%s\n", len(gr), v.options.Organization, v.options.Repo)
+
+	inRange := map[string]string{}
+	selected := map[string]string{}
+	skipped := []releaseDecision{}
+	for _, g := range gr {
+		d, err := v.decide(g, constraint)
+		if err != nil {
+			return "", err
+		}
+
+		if d.skip != skipOutOfRange && d.skip != skipInvalidVersion {
+			inRange[d.tag] = d.tag
+		}
+
+		if d.skip == "" {
+			selected[d.tag] = d.url
+			continue
+		}
+
+		skipped = append(skipped, d)
+	}
+
+	fmt.Fprintf(sb, "%d releases match the constraint: %s\n", len(inRange), strings.Join(v.SortMapKeys(inRange, true), ", "))
+	fmt.Fprintf(sb, "%d releases have an asset for %s/%s: %s\n", len(selected), v.options.GOOS, v.options.GOARCH, strings.Join(v.SortMapKeys(selected, true), ", "))
+
+	for _, d := range skipped {
+		fmt.Fprintf(sb, "Skipped %s: %s\n", d.tag, d.skip)
+	}
+
+	v.explainSelected(ctx, sb, constraint, v.SortMapKeys(selected, true))
+
+	return sb.String(), nil
+}
+
+// ExplainReleases returns the decision made for each release when filtering the releases with
+// the constraint, in the order GitHub lists them, showing why releases are skipped. Releases are
+// read from the same source as ListReleases, the installed versions in descending order when
+// Frozen is set and the git tags when UseTags is set, or FallbackToTags is set and there are
+// no releases. Returns an error when the constraint is invalid
+func (v *VersionsImpl) ExplainReleases(constraint string) ([]ReleaseDecision, error) {
+	ctx := context.Background()
+	constraint = v.constraint(constraint)
+
+	var c *semver.Constraints
+	if constraint != "" {
+		var err error
+
+		c, err = semver.NewConstraint(constraint)
+		if err != nil {
+			return nil, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+		}
+	}
+
+	if v.options.Frozen {
+		return v.explainInstalled(constraint, c)
+	}
+
+	if v.options.UseTags {
+		return v.explainTags(ctx, constraint, c)
+	}
+
+	gr, err := v.listGitHubReleasesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gr) == 0 && v.options.FallbackToTags {
+		return v.explainTags(ctx, constraint, c)
+	}
+
+	decisions := []ReleaseDecision{}
+	for _, g := range gr {
+		ver, err := v.parseVersion(g.GetTagName())
+		if err != nil {
+			decisions = append(decisions, ReleaseDecision{Tag: g.GetTagName(), Reason: skipInvalidVersion})
+			continue
+		}
+
+		d, err := v.decide(g, constraint)
+		if err != nil {
+			return nil, err
+		}
+
+		// semantic version constraints do not match pre-releases unless the constraint
+		// contains a pre-release, report these separately as the version is in range
+		if d.skip == skipOutOfRange && ver.Prerelease() != "" && c.Check(releaseVersion(ver)) {
+			d.skip = skipPrerelease
+		}
+
+		decisions = append(decisions, ReleaseDecision{Tag: d.tag, URL: d.url, Included: d.skip == "", Reason: d.skip})
+	}
+
+	return decisions, nil
+}
+
+// explainTags returns the decision for each git tag in the order GitHub lists them
+func (v *VersionsImpl) explainTags(ctx context.Context, constraint string, c *semver.Constraints) ([]ReleaseDecision, error) {
+	gt, err := v.listTagsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := []ReleaseDecision{}
+	for _, t := range gt {
+		decisions = append(decisions, v.decideVersion(t.GetName(), v.sourceURL(t.GetName()), constraint, c))
+	}
+
+	return decisions, nil
+}
+
+// explainInstalled returns the decision for each installed version in descending order,
+// returns ErrFrozen when no versions are installed
+func (v *VersionsImpl) explainInstalled(constraint string, c *semver.Constraints) ([]ReleaseDecision, error) {
+	installed, err := v.listInstalledVersions("")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(installed) == 0 {
+		return nil, ErrFrozen
+	}
+
+	decisions := []ReleaseDecision{}
+	for _, tag := range v.SortMapKeys(installed, true) {
+		decisions = append(decisions, v.decideVersion(tag, installed[tag], constraint, c))
+	}
+
+	return decisions, nil
+}
+
+// decideVersion returns the decision for a version which does not have assets to select from,
+// such as a git tag or an installed version, the version is included when it matches the constraint
+func (v *VersionsImpl) decideVersion(tag, url, constraint string, c *semver.Constraints) ReleaseDecision {
+	ver, err := v.parseVersion(tag)
+	if err != nil {
+		return ReleaseDecision{Tag: tag, Reason: skipInvalidVersion}
+	}
+
+	if constraint != "" {
+		if valid, err := v.InRange(tag, constraint); err != nil || !valid {
+			d := ReleaseDecision{Tag: tag, Reason: skipOutOfRange}
+			if ver.Prerelease() != "" && c.Check(releaseVersion(ver)) {
+				d.Reason = skipPrerelease
+			}
+
+			return d
+		}
+	}
+
+	return ReleaseDecision{Tag: tag, URL: url, Included: true}
+}
+
+// explainSelected writes the selected release given the candidate tags in descending order
+func (v *VersionsImpl) explainSelected(ctx context.Context, sb *strings.Builder, constraint string, tags []string) {
+	if len(tags) > 0 {
+		fmt.Fprintf(sb, "Selected %s, the highest version of the %d candidates\n", tags[0], len(tags))
+		return
+	}
+
+	if v.onlyPrereleasesMatch(ctx, constraint) {
+		fmt.Fprintln(sb, "No release selected, only pre-releases match the constraint and IncludePrerelease is not set")
+		return
+	}
+
+	fmt.Fprintln(sb, "No release selected")
+}
@@ -0,0 +1,164 @@
+package gvm
+
+import (
+	"path"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestExplainReportsEachFilteringStep(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.15.0", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-darwin")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.0")
+
+	e, err := v.Explain("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Contains(t, e, "Fetched 4 releases from nicholasjackson/fake-service")
+	assert.Contains(t, e, "3 releases match the constraint: v0.14.2, v0.14.1, v0.14.0")
+	assert.Contains(t, e, "1 releases have an asset for linux/x64: v0.14.1")
+	assert.Contains(t, e, "Skipped v0.15.0: does not match the constraint")
+	assert.Contains(t, e, "Skipped v0.14.2: has no asset for the platform")
+	assert.Contains(t, e, "Skipped v0.14.0: has no assets")
+	assert.Contains(t, e, "Selected v0.14.1")
+}
+
+func TestExplainSkipsReleasesWhichAreNotSemanticVersions(t *testing.T) {
+	for _, constraint := range []string{"", "~0.14.0"} {
+		_, v, s := setupStub(t)
+		s.addRelease("nightly", "fake-service-linux")
+		s.addRelease("v0.14.1", "fake-service-linux")
+
+		e, err := v.Explain(constraint)
+		assert.NoError(t, err)
+
+		assert.Contains(t, e, "1 releases match the constraint: v0.14.1\n", constraint)
+		assert.Contains(t, e, "1 releases have an asset for linux/x64: v0.14.1\n", constraint)
+		assert.Contains(t, e, "Skipped nightly: is not a semantic version", constraint)
+		assert.Contains(t, e, "Selected v0.14.1", constraint)
+	}
+}
+
+func TestExplainReportsNoSelectedRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	e, err := v.Explain("~1.0.0")
+	assert.NoError(t, err)
+
+	assert.Contains(t, e, "0 releases match the constraint")
+	assert.Contains(t, e, "No release selected")
+}
+
+func TestExplainReportsOnlyPrereleasesMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.15.0-beta.1", "fake-service-linux")
+
+	e, err := v.Explain("~0.15.0")
+	assert.NoError(t, err)
+
+	assert.Contains(t, e, "only pre-releases match the constraint")
+}
+
+func TestExplainReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.Explain("abc")
+	assert.Error(t, err)
+}
+
+func TestExplainReleasesRecordsEachSkipReason(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("nightly", "fake-service-linux")
+	s.addRelease("v0.15.0", "fake-service-linux")
+	s.addRelease("v0.14.3-beta.1", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-darwin")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.0")
+
+	d, err := v.ExplainReleases("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []ReleaseDecision{
+		{Tag: "nightly", Reason: skipInvalidVersion},
+		{Tag: "v0.15.0", Reason: skipOutOfRange},
+		{Tag: "v0.14.3-beta.1", Reason: skipPrerelease},
+		{Tag: "v0.14.2", Reason: skipNoMatchingAsset},
+		{Tag: "v0.14.1", URL: s.URL + "/download/v0.14.1/fake-service-linux", Included: true},
+		{Tag: "v0.14.0", Reason: skipNoAssets},
+	}, d)
+}
+
+func TestExplainReleasesIncludesPrereleaseWhenIncludePrereleaseSet(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.IncludePrerelease = true
+	s.addRelease("v0.14.3-beta.1", "fake-service-linux")
+
+	d, err := v.ExplainReleases("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, d, 1)
+	assert.True(t, d[0].Included)
+}
+
+func TestExplainReleasesReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.ExplainReleases("abc")
+	assert.Error(t, err)
+}
+
+func TestExplainReleasesUsesTags(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.UseTags = true
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.tags = append(s.tags,
+		&github.RepositoryTag{Name: github.String("v0.15.0")},
+		&github.RepositoryTag{Name: github.String("v0.14.1")},
+		&github.RepositoryTag{Name: github.String("nightly")},
+	)
+
+	d, err := v.ExplainReleases("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []ReleaseDecision{
+		{Tag: "v0.15.0", Reason: skipOutOfRange},
+		{Tag: "v0.14.1", URL: "https://github.com/nicholasjackson/fake-service/archive/v0.14.1.tar.gz", Included: true},
+		{Tag: "nightly", Reason: skipInvalidVersion},
+	}, d)
+
+	r, err := v.ListReleases("~0.14.0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"v0.14.1": d[1].URL}, r)
+	assert.Equal(t, 0, s.releasePages)
+}
+
+func TestExplainReleasesUsesInstalledVersionsWhenFrozen(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.Frozen = true
+	installExe(t, tmp, "v0.14.1", "v0.14.1")
+	installExe(t, tmp, "v0.15.0", "v0.15.0")
+
+	d, err := v.ExplainReleases("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []ReleaseDecision{
+		{Tag: "v0.15.0", Reason: skipOutOfRange},
+		{Tag: "v0.14.1", URL: path.Join(tmp, "v0.14.1", "fake-service-linux"), Included: true},
+	}, d)
+}
+
+func TestExplainReleasesReturnsErrorWhenFrozenAndNothingInstalled(t *testing.T) {
+	_, v := setup(t)
+	v.options.Frozen = true
+
+	_, err := v.ExplainReleases("")
+	assert.True(t, xerrors.Is(err, ErrFrozen))
+}
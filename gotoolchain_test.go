@@ -0,0 +1,76 @@
+package gvm
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupGoToolchain(t *testing.T) (string, *VersionsImpl, *stubGitHub) {
+	tmp, v, s := setupStub(t)
+	v.options.GoToolchainMode = true
+	v.options.AssetNameFunc = goAssetName
+	v.options.ExeNameFunc = goExeName
+
+	s.addRelease("go1.21.10", "go1.21.10.linux-x64.tar.gz")
+	s.addRelease("go1.21.3", "go1.21.3.linux-x64.tar.gz")
+	s.addRelease("go1.20", "go1.20.linux-x64.tar.gz")
+
+	return tmp, v, s
+}
+
+func TestGoToolchainModeResolvesGoTags(t *testing.T) {
+	_, v, s := setupGoToolchain(t)
+
+	r, err := v.ListReleases("~1.21.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 2)
+	assert.Equal(t, s.URL+"/download/go1.21.3/go1.21.3.linux-x64.tar.gz", r["go1.21.3"])
+
+	tag, _, err := v.GetLatestReleaseURL("")
+	assert.NoError(t, err)
+	assert.Equal(t, "go1.21.10", tag)
+
+	tag, _, err = v.GetLatestReleaseURL("<1.21.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "go1.20", tag)
+}
+
+func TestGoToolchainModeDownloadsDistribution(t *testing.T) {
+	tmp, v, s := setupGoToolchain(t)
+	s.assets["/download/go1.21.3/go1.21.3.linux-x64.tar.gz"] = tarGz(t, map[string]string{"go/bin/go": "binary"})
+
+	fp, err := v.DownloadRelease("go1.21.3", s.URL+"/download/go1.21.3/go1.21.3.linux-x64.tar.gz")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "go1.21.3", "go", "bin", "go"), fp)
+	assert.FileExists(t, fp)
+}
+
+func TestGoToolchainModeNextVersionKeepsPrefix(t *testing.T) {
+	_, v, _ := setupGoToolchain(t)
+
+	n, err := v.NextVersion("go1.21.3", BumpMinor)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "go1.22.0", n)
+}
+
+func TestGoToolchainModeNormalizesGoPrefix(t *testing.T) {
+	_, v, _ := setupGoToolchain(t)
+
+	assert.Equal(t, "1.21.3", v.NormalizeTag("go1.21.3"))
+	assert.Equal(t, "1.21.3", v.NormalizeTag("v1.21.3"))
+	assert.Equal(t, "go1.21.3", v.DenormalizeTag("1.21.3"))
+	assert.Equal(t, "go1.21.3", v.DenormalizeTag("go1.21.3"))
+}
+
+func TestNewDefaultsGoToolchainNames(t *testing.T) {
+	v := New(Options{GoToolchainMode: true}).(*VersionsImpl)
+
+	assert.Equal(t, "go1.21.3.linux-amd64.tar.gz", v.options.AssetNameFunc("1.21.3", "linux", "amd64"))
+	assert.Equal(t, "go1.21.3.windows-amd64.zip", v.options.AssetNameFunc("1.21.3", "windows", "amd64"))
+	assert.Equal(t, "go/bin/go", v.options.ExeNameFunc("1.21.3", "linux", "amd64"))
+}
@@ -2,21 +2,54 @@ package gvm
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/google/go-github/github"
-	"github.com/hashicorp/go-getter"
 	"golang.org/x/xerrors"
 )
 
 type Archive int
 
+// ErrFrozen is returned in Frozen mode when an operation would need network access
+var ErrFrozen = xerrors.New("Version is not installed and network access is disabled in frozen mode")
+
+// ErrVersionMismatch is returned when the version reported by an installed executable does not match the release tag
+var ErrVersionMismatch = xerrors.New("Installed version does not match the release")
+
+// ErrOnlyPrereleasesMatch is returned when the only releases matching a constraint are
+// pre-releases and IncludePrerelease is not set
+var ErrOnlyPrereleasesMatch = xerrors.New("Only pre-releases match the constraint")
+
+// ErrNotInstalled is returned when a version is not installed
+var ErrNotInstalled = xerrors.New("Version is not installed")
+
+// ErrVersionOutOfRange is returned when a tag does not satisfy the constraint it is checked against
+var ErrVersionOutOfRange = xerrors.New("Version does not satisfy the constraint")
+
+// ErrNotManaged is returned when a path is not an installed version in ReleasesPath
+var ErrNotManaged = xerrors.New("Path is not a version managed in the releases path")
+
+// osSynonyms are the alternate names commonly used in asset names for each GOOS
+var osSynonyms = map[string][]string{
+	"darwin":  {"macos", "osx", "mac"},
+	"windows": {"win"},
+}
+
 // Options defines the options for Versions
 type Options struct {
 	Organization  string
@@ -26,6 +59,173 @@ type Options struct {
 	AssetNameFunc func(ver, goos, goarch string) string
 	ExeNameFunc   func(ver, goos, goarch string) string
 	ReleasesPath  string // location to store donwloaded releases
+	// SavedBinaryName when set is the file name the executable is saved as in
+	// place of the name returned from ExeNameFunc, ExeNameFunc is still used to
+	// locate the executable in the downloaded asset and to find versions installed
+	// before SavedBinaryName was set
+	SavedBinaryName string
+	// ConstraintEnvVar is the name of an environment variable which is read
+	// for the semantic version constraint when an empty constraint is passed
+	// to a method which resolves versions with a constraint such as ListReleases or
+	// ListInstalledVersions, an explicit constraint always wins. Methods which do not
+	// take a constraint, e.g. InstalledStats, always see every version
+	ConstraintEnvVar string
+	// UseOSSynonyms calls AssetNameFunc with common synonyms for GOOS such as
+	// macos and osx for darwin when no asset matches GOOS
+	UseOSSynonyms bool
+	// PlatformMapFunc when set maps the GOOS and GOARCH to the names used by the project
+	// before they are passed to AssetNameFunc when matching assets, e.g. amd64 to x86_64
+	// or darwin to macos, so that AssetNameFunc does not need to transform them
+	PlatformMapFunc func(goos, goarch string) (mappedOS, mappedArch string)
+	// Frozen forbids any network access, ListReleases, GetLatestReleaseURL and
+	// DownloadRelease are resolved from the installed versions and return ErrFrozen
+	// when the requested version is not installed
+	Frozen bool
+	// ChecksumFunc returns the expected hex encoded sha256 checksum of the asset
+	// for a release, verification is skipped when nil or an empty string is returned.
+	// A Subresource Integrity string such as sha384-<base64> can be returned in place
+	// of the hex checksum, sha256, sha384 and sha512 are supported
+	ChecksumFunc func(ver, goos, goarch string) string
+	// QuarantineOnFailure moves assets which fail verification to ReleasesPath/.quarantine
+	// for later inspection rather than deleting them
+	QuarantineOnFailure bool
+	// PreferArchive selects the archive rather than the raw binary when a release
+	// contains both for the asset name returned from AssetNameFunc
+	PreferArchive bool
+	// UseTags lists the git tags for the repository in place of GitHub releases, for
+	// repositories which do not publish releases. Tags have no assets so the URL
+	// returned for a tag is the source archive
+	UseTags bool
+	// FallbackToTags lists the git tags for the repository when it has no GitHub releases,
+	// as with UseTags the URL returned for a tag is the source archive
+	FallbackToTags bool
+	// Concurrency is the maximum number of parallel downloads for batch
+	// operations such as DownloadLatestN, defaults to 4
+	Concurrency int
+	// MaxBytesPerSecond when set limits the rate assets are downloaded, the limit is shared
+	// by concurrent downloads. When zero downloads are not throttled
+	MaxBytesPerSecond int64
+	// MaxRedirects is the maximum number of redirects followed when downloading
+	// an asset, defaults to 10
+	MaxRedirects int
+	// StripComponents is the number of leading path components removed from the files
+	// in an archive when it is uncompressed, like tar --strip-components
+	StripComponents int
+	// RequiredAuxAssetsFunc returns the names of the assets such as checksums or signatures
+	// which a release must contain in addition to the binary to be installable
+	RequiredAuxAssetsFunc func(ver string) []string
+	// NoAssetBehavior defines how releases which match the constraint but have no
+	// assets are handled, defaults to NoAssetSkip
+	NoAssetBehavior NoAssetBehavior
+	// ListRetries is the number of times listing releases or tags is retried when GitHub
+	// returns a 5xx status or the connection is reset, defaults to 3, set to a negative
+	// value to disable retries
+	ListRetries int
+	// RetryBackoff is the delay before the first retry, the delay doubles for each
+	// subsequent retry, defaults to 1 second
+	RetryBackoff time.Duration
+	// RetryJitter is the fraction of the backoff randomly added to or subtracted from
+	// each retry delay so that many clients do not retry at the same time,
+	// e.g. 0.2 gives a delay between 80% and 120% of the backoff, defaults to 0
+	RetryJitter float64
+	// Backoff when set defines the delay between retries, replacing RetryBackoff and RetryJitter
+	Backoff Backoff
+	// GitHubTimeout is the maximum time for each GitHub API request, including reading
+	// the response, defaults to 30 seconds, set to a negative value to disable the timeout
+	GitHubTimeout time.Duration
+	// DownloadTimeout is the maximum time for downloading an asset, including reading
+	// the body, defaults to no timeout so that large assets on slow connections complete
+	DownloadTimeout time.Duration
+	// TempMaxAge is the time since a temporary download folder was last modified after
+	// which CleanupTemp removes it, defaults to 24 hours
+	TempMaxAge time.Duration
+	// VersionFileName is the name of the file read by ResolveFromDir, defaults to .tool-version
+	VersionFileName string
+	// VersionProbeFunc when set is called with the path of the executable after a version
+	// is installed and returns the version reported by the executable, when it does not
+	// match the release tag the version is removed and ErrVersionMismatch returned
+	VersionProbeFunc func(path string) (string, error)
+	// IncludePrerelease includes pre-releases of versions which match a constraint, by default
+	// pre-releases only match constraints which contain a pre-release e.g. ">= 1.2.0-0"
+	IncludePrerelease bool
+	// CacheTTL is the length of time the releases listed for a constraint are cached,
+	// releases are not cached when 0 unless kept warm with StartBackgroundRefresh
+	CacheTTL time.Duration
+	// ContentAddressedStore keeps downloaded assets in ReleasesPath/.blobs/<sha256>, assets
+	// which are not archives are linked into the version folder while archives are extracted
+	// into each version folder. Assets with a checksum from ChecksumFunc which are already in
+	// the store are not downloaded again
+	ContentAddressedStore bool
+	// MinisignPublicKey when set verifies each downloaded asset with the minisign signature
+	// published as an asset with the .minisig extension. The key is either the base64 encoded
+	// public key or the content of a minisign public key file
+	MinisignPublicKey string
+	// CacheProxyURL is the URL of a caching proxy which assets are downloaded through,
+	// the asset host and path are appended to the proxy URL e.g.
+	// https://proxy.example.com/github.com/org/repo/releases/download/v1.0.0/asset
+	// When the proxy fails or returns an asset which does not match the checksum
+	// from ChecksumFunc the asset is downloaded directly. The proxy is only used for
+	// releases which ChecksumFunc returns a checksum for, as the proxied asset can not
+	// otherwise be verified
+	CacheProxyURL string
+	// SelectLargestExecutable installs the largest executable file in an archive as the
+	// executable when the archive does not contain the file named by ExeNameFunc
+	SelectLargestExecutable bool
+	// CleanExtras removes every file in the version folder other than the executable and the
+	// files returned from ExtraFilesFunc once an asset has been uncompressed
+	CleanExtras bool
+	// ExtraFilesFunc returns the paths, relative to the version folder, of the files in an
+	// archive which are kept alongside the executable when CleanExtras is set
+	ExtraFilesFunc func(ver, goos, goarch string) []string
+	// DetectNativeArch detects when a darwin amd64 process is running under Rosetta on
+	// Apple Silicon and prefers arm64 assets, falling back to GOARCH when a release does
+	// not have an arm64 asset. AssetNameFunc is called with arm64 when matching the native asset
+	DetectNativeArch bool
+	// RelativePaths returns the paths of installed executables relative to ReleasesPath rather
+	// than joined to ReleasesPath so that the paths remain valid when ReleasesPath is moved.
+	// Affects the paths returned by DownloadRelease, DownloadLatestN, ResumeBatch, InstallAs,
+	// GetByLabel, InstallAndActivate, ListInstalledVersions, GetInstalledVersion, InstalledPath,
+	// GetInstalledPathIgnoringBuild, ListHealthyInstalled and InstalledVersionsSorted
+	RelativePaths bool
+	// MaxReleases when set limits the number of releases fetched from GitHub to the most
+	// recent MaxReleases, reducing the requests made for repositories with many releases.
+	// Constraints which only match older releases will not find a release
+	MaxReleases int
+	// Scheme when set selects the asset naming function registered with RegisterScheme in place
+	// of AssetNameFunc, built in schemes are provided for kind, k3d, minikube and k9s.
+	// ExeNameFunc is still used to locate the executable in the downloaded asset
+	Scheme string
+	// GoToolchainMode resolves Go toolchain tags such as go1.21.3, the go prefix is removed
+	// when parsing the tag as a semantic version and the version without the prefix is passed
+	// to AssetNameFunc, ExeNameFunc and ChecksumFunc. When AssetNameFunc or ExeNameFunc are
+	// not set they default to the Go distribution names e.g. go1.21.3.linux-amd64.tar.gz
+	// containing go/bin/go
+	GoToolchainMode bool
+	// TagPrefix is the prefix of the release tags which is removed to give the semantic
+	// version and added to a version to give the tag e.g. release- for release-1.2.3,
+	// defaults to v. GoToolchainMode always uses the go prefix
+	TagPrefix string
+	// AllowedSchemes is the list of URL schemes assets can be downloaded from,
+	// defaults to https
+	AllowedSchemes []string
+	// AllowedHosts when set is the list of hosts assets can be downloaded from, an entry can
+	// include a path to restrict downloads to URLs below the path, e.g. github.com/myorg/*.
+	// Redirects are checked against the same list
+	AllowedHosts []string
+	// SetCurrentOnInstall sets a version downloaded with DownloadRelease as the current version
+	// when it is the highest installed version, a current version higher than the download is kept
+	SetCurrentOnInstall bool
+	// ActivationMode defines how SetCurrent places the executable of the active version at
+	// ReleasesPath/bin/<name>, defaults to ActivationSymlink or ActivationCopy on Windows.
+	// The name is SavedBinaryName when set otherwise the repo name
+	ActivationMode ActivationMode
+	// Layout defines how installed versions are arranged in ReleasesPath
+	// defaults to LayoutFlat
+	Layout LayoutKind
+	// MetadataStore persists the metadata recorded when a version is installed
+	// defaults to a SidecarMetadataStore in the version folders when nil. Versions installed
+	// with DownloadReleaseForTarget always use a SidecarMetadataStore
+	MetadataStore MetadataStore
 }
 
 // Versions defines the methods for a Go Version Manager implementation
@@ -35,26 +235,175 @@ type Versions interface {
 	// Optionally specify a semantic version contstraint to filter results
 	// e.g. "~1.2.3", version is greater or equal to 1.2.3 and less than 1.3.0
 	ListReleases(constraint string) (map[string]string, error)
+	// ListVerifiableReleases lists the releases matching the constraint which have an asset for the platform and a checksums asset
+	ListVerifiableReleases(constraint string) (map[string]string, error)
+	// GetRawRelease returns the GitHub release for the given tag
+	GetRawRelease(tag string) (*github.RepositoryRelease, error)
+	// Warm lists the releases from GitHub once and caches the releases matching each of the constraints
+	Warm(ctx context.Context, constraints ...string) error
+	// StartBackgroundRefresh periodically refreshes the cached releases for the constraint until ctx is done
+	StartBackgroundRefresh(ctx context.Context, constraint string, interval time.Duration)
+	// GetReleaseURLChecked returns the asset URL for the release with the given tag when the tag satisfies the constraint
+	GetReleaseURLChecked(tag, constraint string) (url string, err error)
+	// GetReleaseByID returns the release with the given GitHub release ID
+	GetReleaseByID(id int64) (Release, error)
+	// DownloadAssetByID downloads and uncompresses the asset with the given ID from the release with the given ID
+	DownloadAssetByID(releaseID, assetID int64) (path string, err error)
+	// FindAssets returns the assets for the release with the given tag which match the predicate
+	FindAssets(tag string, match AssetMatchFunc) ([]Asset, error)
+	// DownloadAssetMatching downloads the single asset for the release with the given tag which matches the predicate
+	DownloadAssetMatching(tag string, match AssetMatchFunc) (path string, err error)
+	// DownloadReleaseAssets downloads every asset for the release with the given tag whose name matches the glob into destDir
+	DownloadReleaseAssets(tag, glob, destDir string) (map[string]string, error)
+	// DiffAssets returns the names of the assets added and removed between the releases with the given tags
+	DiffAssets(tagA, tagB string) (added, removed []string, err error)
+	// ListReleasesWithAssetFunc lists the releases matching the constraint using fn in place of AssetNameFunc
+	ListReleasesWithAssetFunc(constraint string, fn func(ver, goos, goarch string) string) (map[string]string, error)
+	// ListReleasesSince returns the releases published after the release with the given tag, newest first
+	ListReleasesSince(tag string) ([]Release, error)
+	// CompleteVersions returns the release tags starting with prefix sorted in descending order
+	CompleteVersions(prefix string) ([]string, error)
 	// GetLatestRelease returns the asset for the latest release given the constraint
 	GetLatestReleaseURL(constraint string) (tag string, url string, err error)
+	// ResolveAll returns the latest release which satisfies every one of the constraints
+	ResolveAll(constraints []string) (tag, url string, err error)
+	// Explain returns a human readable trace of how the release for the constraint is selected
+	Explain(constraint string) (string, error)
+	// ExplainReleases returns the decision made for each release when filtering the releases with the constraint
+	ExplainReleases(constraint string) ([]ReleaseDecision, error)
+	// SuggestVersions returns the releases closest to a constraint which has no matching releases
+	SuggestVersions(constraint string) ([]string, error)
+	// ResolveFromDir returns the latest release matching the version file in dir or its parents
+	ResolveFromDir(dir string) (tag string, err error)
+	// LatestPerMinor returns the latest release tag for each major.minor version matching the constraint
+	LatestPerMinor(constraint string) (map[string]string, error)
+	// LoadPresets loads named semantic version constraints from a JSON or YAML document
+	LoadPresets(data []byte) error
+	// GetLatestForPreset returns the asset for the latest release matching the named preset
+	GetLatestForPreset(name string) (tag, url string, err error)
+	// GetEffectiveLatest returns the asset for the release GitHub marks as latest when it
+	// satisfies the constraint, otherwise the latest release by semantic version
+	GetEffectiveLatest(constraint string) (tag string, url string, err error)
+	// GetTaggedRelease resolves a named git tag such as stable to the release referencing the same commit
+	GetTaggedRelease(gitTag string) (tag string, url string, err error)
+	// GetLatestStable returns the asset for the latest release matching the constraint published at least minAge ago
+	GetLatestStable(constraint string, minAge time.Duration) (tag string, url string, err error)
+	// GetLatestExcluding returns the asset for the latest release matching the constraint other than excludeTag
+	GetLatestExcluding(constraint, excludeTag string) (tag string, url string, err error)
+	// GetAssetModTime returns the time the asset for the given release was last updated
+	GetAssetModTime(tag string) (time.Time, error)
 	// Download and uncompress the release at the given url
 	DownloadRelease(tag, url string) (path string, err error)
+	// InstallEphemeral downloads and uncompresses the release into a temporary folder returning a function which removes it
+	InstallEphemeral(tag, url string) (path string, cleanup func() error, err error)
+	// InstallFromURL downloads and installs the asset at url as the version tag without calling GitHub
+	InstallFromURL(tag, url string) (path string, err error)
+	// DownloadReleaseForTarget downloads and uncompresses the release for the given platform
+	// into ReleasesPath/<goos>_<goarch>/<tag>
+	DownloadReleaseForTarget(tag, url, goos, goarch string) (path string, err error)
+	// DownloadReleaseToWriter streams the raw asset at the given URL to w
+	DownloadReleaseToWriter(ctx context.Context, url string, w io.Writer) error
+	// InstallLatestAndPrune installs the latest release matching the constraint and removes older installed versions beyond keep
+	InstallLatestAndPrune(constraint string, keep int) (tag string, removed []string, err error)
+	// InstallAs installs the latest release matching the constraint and records it with the label
+	InstallAs(label, constraint string) (tag, path string, err error)
+	// GetByLabel returns the tag and path of the version installed with the label
+	GetByLabel(label string) (tag, path string, err error)
+	// CleanupTemp removes temporary folders older than TempMaxAge left by interrupted downloads, returning the number removed
+	CleanupTemp() (int, error)
+	// DownloadLatestN downloads the latest n releases matching the constraint in parallel
+	// returning the paths and errors keyed by tag
+	DownloadLatestN(constraint string, n int) (map[string]string, map[string]error)
+	// ResumeBatch installs the given release tags which are not already installed and healthy
+	ResumeBatch(tags []string) (map[string]string, map[string]error)
 	// ListInstalledVersions lists versions which have been installed
 	ListInstalledVersions(constraint string) (map[string]string, error)
 	// GetInstalledVersion returns the version for the latests release given the constraint
 	GetInstalledVersion(constraint string) (tag string, path string, err error)
+	// InstalledSatisfies returns true and the tag of the highest installed version when an installed version matches the constraint
+	InstalledSatisfies(constraint string) (satisfied bool, tag string, err error)
+	// InstalledPath returns the path of the executable installed for the exact tag
+	InstalledPath(tag string) (string, error)
+	// GetInstalledPathIgnoringBuild returns the path of the installed version matching version ignoring build metadata
+	GetInstalledPathIgnoringBuild(version string) (path string, err error)
+	// UpgradeWithinMajor returns the latest release with the same major version as the
+	// latest installed version, upgradeAvailable is true when it is newer than the installed version
+	UpgradeWithinMajor() (tag, url string, upgradeAvailable bool, err error)
+	// NextReleaseVersion returns the version which follows the latest release for the bump
+	NextReleaseVersion(bump BumpKind) (string, error)
+	// NextVersion returns the version which follows current for the bump
+	NextVersion(current string, bump BumpKind) (string, error)
+	// IsBreakingUpgrade returns true when upgrading from one version to another
+	// crosses a semantic version breaking change boundary
+	IsBreakingUpgrade(from, to string) (bool, error)
+	// WhichInstalled returns the tag of the installed version for the given executable path
+	// Returns ErrNotManaged if the path is not an installed version
+	WhichInstalled(exePath string) (tag string, err error)
+	// VerifyIntegrity checks the installed executable for the tag against the checksum recorded at install
+	VerifyIntegrity(tag string) error
+	// ListHealthyInstalled returns the installed versions matching the constraint which pass VerifyIntegrity
+	ListHealthyInstalled(constraint string) (map[string]string, error)
+	// VerifyAll runs VerifyIntegrity in parallel for the installed versions matching the constraint
+	VerifyAll(constraint string) (map[string]error, error)
+	// GenerateEnv returns the environment variables which select the installed version for the tag
+	GenerateEnv(tag string) (map[string]string, error)
+	// InstalledStats returns the number of installed versions and the tags of the highest and lowest installed versions
+	InstalledStats() (count int, latest, oldest string, err error)
+	// ListWithInstallState returns the releases matching the constraint newest first marking those which are installed
+	ListWithInstallState(constraint string) ([]VersionState, error)
+	// InstalledVersionsSorted returns the installed versions sorted by semantic version
+	InstalledVersionsSorted(descending bool) ([]InstalledVersion, error)
+	// InstalledOlderThan returns the installed versions installed more than d ago, excluding the current version
+	InstalledOlderThan(d time.Duration) ([]string, error)
+	// StaleInstalled returns the installed versions older than the latest release matching the constraint
+	StaleInstalled(constraint string) ([]string, error)
+	// NormalizeTag returns the version for a release tag by removing the release tag prefix
+	NormalizeTag(tag string) string
+	// DenormalizeTag returns the release tag for a version by adding the release tag prefix
+	DenormalizeTag(version string) string
 	// SortMapKeys sorts the keys in the map and returns a sorted slice
 	// keys must adhere to Semver
 	SortMapKeys(map[string]string, bool) []string
+	// MigrateLayout moves the installed versions to the target layout returning the migrated tags
+	MigrateLayout(target LayoutKind) ([]string, error)
+	// SetCurrent sets the active version by writing the current version pointer file
+	SetCurrent(tag string) error
+	// InstallAndActivate installs the latest release matching the constraint and sets it as the current version
+	InstallAndActivate(constraint string) (tag, path string, err error)
+	// GetCurrent returns the active version from the current version pointer file
+	GetCurrent() (string, error)
+	// ListQuarantine returns the assets which failed verification and were quarantined
+	ListQuarantine() ([]QuarantinedAsset, error)
+	// ClearQuarantine removes all quarantined assets
+	ClearQuarantine() error
+	// VerifyReleaseCommit checks that the release tag still references the commit recorded at install
+	VerifyReleaseCommit(tag string) (bool, error)
+	// Ping checks GitHub can be reached, returning an error describing the cause when it can not
+	Ping() error
+	// ListOrgRepos returns the names of the repositories in a GitHub organization
+	ListOrgRepos(org string) ([]string, error)
 	// InRange returns true when the version can be satisfied by the constraint
 	// Returns an error if either the constraint or the version are not valid semantic versions
 	InRange(version string, constraint string) (bool, error)
 }
 
+// InstalledVersion describes a version which has been installed to the ReleasesPath
+type InstalledVersion struct {
+	Tag         string
+	Path        string
+	Size        int64
+	InstallTime time.Time
+}
+
+// VersionState is an available release and whether it has been installed to the ReleasesPath
+type VersionState struct {
+	Tag       string
+	URL       string
+	Installed bool
+}
+
 // New creates a new Versions for the given options
 func New(o Options) Versions {
-	client := github.NewClient(nil)
-
 	if o.GOARCH == "" {
 		o.GOARCH = runtime.GOARCH
 	}
@@ -63,13 +412,89 @@ func New(o Options) Versions {
 		o.GOOS = runtime.GOOS
 	}
 
-	return &VersionsImpl{o, client}
+	if o.Concurrency == 0 {
+		o.Concurrency = defaultConcurrency
+	}
+
+	if o.MaxRedirects == 0 {
+		o.MaxRedirects = defaultMaxRedirects
+	}
+
+	if o.ListRetries == 0 {
+		o.ListRetries = defaultListRetries
+	}
+
+	if o.RetryBackoff == 0 {
+		o.RetryBackoff = defaultRetryBackoff
+	}
+
+	if o.GitHubTimeout == 0 {
+		o.GitHubTimeout = defaultGitHubTimeout
+	}
+
+	if o.TempMaxAge == 0 {
+		o.TempMaxAge = defaultTempMaxAge
+	}
+
+	if o.VersionFileName == "" {
+		o.VersionFileName = defaultVersionFileName
+	}
+
+	if o.TagPrefix == "" {
+		o.TagPrefix = tagPrefix
+	}
+
+	if len(o.AllowedSchemes) == 0 {
+		o.AllowedSchemes = defaultAllowedSchemes
+	}
+
+	if o.ActivationMode == "" {
+		o.ActivationMode = defaultActivationMode(runtime.GOOS)
+	}
+
+	if o.Scheme != "" {
+		o.AssetNameFunc = schemeAssetName(o.Scheme)
+	}
+
+	if o.GoToolchainMode && o.AssetNameFunc == nil {
+		o.AssetNameFunc = goAssetName
+	}
+
+	if o.GoToolchainMode && o.ExeNameFunc == nil {
+		o.ExeNameFunc = goExeName
+	}
+
+	client := github.NewClient(&http.Client{Timeout: o.GitHubTimeout})
+
+	v := &VersionsImpl{options: o, client: client, cache: newReleaseCache(), throttle: newThrottle(o.MaxBytesPerSecond)}
+
+	if o.DetectNativeArch {
+		v.nativeArch = detectNativeArch(o.GOOS, o.GOARCH)
+	}
+
+	return v
 }
 
-// VersionsImpl is the concrete implementation for the Versions interface
+// VersionsImpl is the concrete implementation for the Versions interface.
+// VersionsImpl is safe for concurrent use by multiple goroutines, the Options
+// passed to New must not be modified once the Versions has been created
 type VersionsImpl struct {
 	options Options
 	client  *github.Client
+	cache   *releaseCache
+	// nativeArch is the hardware architecture when it differs from GOARCH
+	nativeArch string
+	// throttle limits the download rate, nil when MaxBytesPerSecond is not set
+	throttle *throttle
+
+	// mutex protects presets and options.Layout which can change after New
+	mutex   sync.RWMutex
+	presets map[string]string
+
+	// labelsMutex serializes updates to the labels file
+	labelsMutex sync.Mutex
+	// currentMutex serializes changes to the current version
+	currentMutex sync.Mutex
 }
 
 // ListReleases returns a map of assets for releases which match
@@ -78,44 +503,164 @@ type VersionsImpl struct {
 // If no version is specified all versions with matching assets are returned
 // Release tags which are not valid semantic versions are ignored
 func (v *VersionsImpl) ListReleases(constraint string) (map[string]string, error) {
-	gr, _, err := v.client.Repositories.ListReleases(context.Background(), v.options.Organization, v.options.Repo, nil)
+	return v.cachedReleases(v.constraint(constraint))
+}
+
+// cachedReleases is ListReleases without reading the constraint from ConstraintEnvVar, an
+// empty constraint always lists every release
+func (v *VersionsImpl) cachedReleases(constraint string) (map[string]string, error) {
+	return v.cachedReleasesContext(context.Background(), constraint)
+}
+
+// cachedReleasesContext is cachedReleases using ctx for any request to GitHub
+func (v *VersionsImpl) cachedReleasesContext(ctx context.Context, constraint string) (map[string]string, error) {
+	if v.options.Frozen {
+		return v.listFrozen(constraint)
+	}
+
+	if r, ok := v.cache.get(constraint, v.options.CacheTTL); ok {
+		return r, nil
+	}
+
+	r, err := v.listReleases(ctx, constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.options.CacheTTL > 0 {
+		v.cache.set(constraint, r, false)
+	}
+
+	return r, nil
+}
+
+// ListReleasesWithAssetFunc lists the releases matching the constraint using fn in place of
+// AssetNameFunc to select the asset for each release. The result is not cached
+func (v *VersionsImpl) ListReleasesWithAssetFunc(constraint string, fn func(ver, goos, goarch string) string) (map[string]string, error) {
+	v.mutex.RLock()
+	o := v.options
+	v.mutex.RUnlock()
+
+	o.AssetNameFunc = fn
+
+	c := &VersionsImpl{options: o, client: v.client, nativeArch: v.nativeArch, throttle: v.throttle}
+
+	return c.ListReleases(constraint)
+}
+
+// listReleases lists the releases matching the constraint from GitHub
+func (v *VersionsImpl) listReleases(ctx context.Context, constraint string) (map[string]string, error) {
+	if v.options.UseTags {
+		return v.listTagReleasesChecked(ctx, constraint)
+	}
+
+	gr, err := v.listGitHubReleasesContext(ctx)
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to list Github releases: %w", err)
+		return nil, err
+	}
+
+	return v.filterReleases(ctx, gr, constraint)
+}
+
+// filterReleases returns the releases matching the constraint with the URL of the asset for the platform
+func (v *VersionsImpl) filterReleases(ctx context.Context, gr []*github.RepositoryRelease, constraint string) (map[string]string, error) {
+	if len(gr) == 0 && v.options.FallbackToTags {
+		return v.listTagReleasesChecked(ctx, constraint)
 	}
 
 	tags := map[string]string{}
 
 	for _, g := range gr {
-		// does this tag match the provided semver
-		if constraint != "" {
-			valid, err := v.InRange(*g.TagName, constraint)
-			if err != nil {
-				return nil, xerrors.Errorf("Invalid sematic version constraint: %w", err)
-			}
-
-			// if the tag does not match continue
-			if !valid {
-				continue
-			}
+		d, err := v.decide(g, constraint)
+		if err != nil {
+			return nil, err
 		}
 
-		// check there is an asset with the given filename
-		for _, a := range g.Assets {
-			tag := strings.TrimLeft(*g.TagName, "v")
-			fn := v.options.AssetNameFunc(tag, v.options.GOOS, v.options.GOARCH)
-			if strings.ToLower(*a.Name) == strings.ToLower(fn) {
-				tags[*g.TagName] = *a.BrowserDownloadURL
-				break
-			}
+		if d.skip == "" {
+			tags[d.tag] = d.url
 		}
 	}
 
 	return tags, nil
 }
 
+// listTagReleasesChecked validates the constraint then lists the git tags which match it
+func (v *VersionsImpl) listTagReleasesChecked(ctx context.Context, constraint string) (map[string]string, error) {
+	if _, err := semver.NewConstraint(constraint); constraint != "" && err != nil {
+		return nil, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+	}
+
+	return v.listTagReleases(ctx, constraint)
+}
+
+// CompleteVersions returns the release tags which start with the given prefix sorted in
+// descending order, assets are not resolved so that the list is quick to produce for
+// shell completion. The prefix matches tags with or without a leading v. When the releases
+// for any version are cached, for example by Warm or StartBackgroundRefresh, the cached
+// releases are returned without calling GitHub
+func (v *VersionsImpl) CompleteVersions(prefix string) ([]string, error) {
+	var names []string
+
+	cached, ok := v.cache.get("", v.options.CacheTTL)
+
+	switch {
+	case v.options.Frozen:
+		installed, err := v.installedVersions("")
+		if err != nil {
+			return nil, err
+		}
+
+		for tag := range installed {
+			names = append(names, tag)
+		}
+	case ok:
+		for tag := range cached {
+			names = append(names, tag)
+		}
+	case v.options.UseTags:
+		gt, err := v.listTags()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range gt {
+			names = append(names, *t.Name)
+		}
+	default:
+		gr, err := v.listGitHubReleases()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range gr {
+			names = append(names, *r.TagName)
+		}
+	}
+
+	matches := map[string]string{}
+	for _, n := range names {
+		if _, err := v.parseVersion(n); err != nil {
+			continue
+		}
+
+		if strings.HasPrefix(n, prefix) || strings.HasPrefix(v.NormalizeTag(n), v.NormalizeTag(prefix)) {
+			matches[n] = n
+		}
+	}
+
+	return v.SortMapKeys(matches, true), nil
+}
+
 // GetLatestRelease returns the asset which has the latest semantic version matching the constraint
 func (v *VersionsImpl) GetLatestReleaseURL(constraint string) (string, string, error) {
-	assets, err := v.ListReleases(constraint)
+	return v.latestReleaseURL(v.constraint(constraint))
+}
+
+// latestReleaseURL is GetLatestReleaseURL without reading the constraint from ConstraintEnvVar
+func (v *VersionsImpl) latestReleaseURL(constraint string) (string, string, error) {
+	ctx := context.Background()
+
+	assets, err := v.cachedReleasesContext(ctx, constraint)
 	if err != nil {
 		return "", "", err
 	}
@@ -123,6 +668,10 @@ func (v *VersionsImpl) GetLatestReleaseURL(constraint string) (string, string, e
 	keys := v.SortMapKeys(assets, false)
 
 	if len(keys) == 0 {
+		if v.onlyPrereleasesMatch(ctx, constraint) {
+			return "", "", xerrors.Errorf("%w: %s", ErrOnlyPrereleasesMatch, constraint)
+		}
+
 		return "", "", nil
 	}
 
@@ -130,103 +679,1351 @@ func (v *VersionsImpl) GetLatestReleaseURL(constraint string) (string, string, e
 	return tag, assets[tag], nil
 }
 
-// DownloadRelease and uncompress the given release
-func (v *VersionsImpl) DownloadRelease(tag, url string) (filePath string, err error) {
-	dir := path.Join(v.options.ReleasesPath, tag)
-	err = os.MkdirAll(dir, os.ModePerm)
-	if err != nil {
-		return "", xerrors.Errorf("Unable to create temporary folder: %w", err)
+// onlyPrereleasesMatch returns true when there are pre-releases which would match the
+// constraint if IncludePrerelease was set, the releases are read from the release cache
+func (v *VersionsImpl) onlyPrereleasesMatch(ctx context.Context, constraint string) bool {
+	if constraint == "" || v.options.IncludePrerelease {
+		return false
 	}
 
-	// if the tag is prefixed with a v remove it
-	tag = strings.TrimLeft(tag, "v")
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
 
-	fp := path.Join(dir, v.options.ExeNameFunc(tag, v.options.GOOS, v.options.GOARCH))
-	err = getter.GetAny(dir, url)
+	all, err := v.cachedReleasesContext(ctx, "")
 	if err != nil {
-		return "", xerrors.Errorf("Unable to download file: %w", err)
+		return false
+	}
+
+	for tag := range all {
+		tv, err := v.parseVersion(tag)
+		if err != nil || tv.Prerelease() == "" {
+			continue
+		}
+
+		if c.Check(releaseVersion(tv)) {
+			return true
+		}
 	}
 
-	return fp, nil
+	return false
 }
 
-// ListInstalledVersions lists the versions of the software which are installed int the archive folder
-func (v *VersionsImpl) ListInstalledVersions(constraint string) (map[string]string, error) {
-	versions := map[string]string{}
+// ResolveAll returns the latest release which satisfies all of the constraints, allowing
+// a version compatible with several components to be found. Returns ErrNoMatchingRelease
+// when no release satisfies every constraint. Pre-releases are matched in the same way as
+// ListReleases
+func (v *VersionsImpl) ResolveAll(constraints []string) (string, string, error) {
+	for _, c := range constraints {
+		_, err := semver.NewConstraint(c)
+		if err != nil {
+			return "", "", xerrors.Errorf("Invalid sematic version constraint %s: %w", c, err)
+		}
+	}
 
-	// list folders at the archive loacation matching the semver
-	files, err := ioutil.ReadDir(v.options.ReleasesPath)
+	assets, err := v.cachedReleases("")
 	if err != nil {
-		return nil, xerrors.Errorf("Unable to list releases: %w", err)
+		return "", "", err
 	}
 
-	for _, f := range files {
-		if constraint != "" {
-			valid, err := v.InRange(f.Name(), constraint)
-			// if the tag does not match continue
-			if err != nil || !valid {
-				continue
+	for _, tag := range v.SortMapKeys(assets, true) {
+		match := true
+		for _, c := range constraints {
+			if ok, err := v.InRange(tag, c); err != nil || !ok {
+				match = false
+				break
 			}
 		}
 
-		tag := strings.TrimLeft(f.Name(), "v")
-		versions[f.Name()] = path.Join(v.options.ReleasesPath, f.Name(), v.options.ExeNameFunc(tag, v.options.GOOS, v.options.GOARCH))
+		if match {
+			return tag, assets[tag], nil
+		}
 	}
 
-	return versions, nil
+	return "", "", xerrors.Errorf("%w: %s", ErrNoMatchingRelease, strings.Join(constraints, " and "))
 }
 
-func (v *VersionsImpl) GetInstalledVersion(constraint string) (string, string, error) {
-	assets, err := v.ListInstalledVersions(constraint)
+// LatestPerMinor groups the releases matching the constraint by their major.minor
+// version and returns the latest release tag in each group keyed by major.minor
+// e.g. {"1.4": "v1.4.2", "2.1": "v2.1.0"}
+func (v *VersionsImpl) LatestPerMinor(constraint string) (map[string]string, error) {
+	assets, err := v.ListReleases(constraint)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	keys := v.SortMapKeys(assets, false)
+	latest := map[string]string{}
 
-	if len(keys) == 0 {
-		return "", "", nil
+	// keys are sorted ascending so the last tag in each group is the latest
+	for _, tag := range v.SortMapKeys(assets, false) {
+		sv, err := v.parseVersion(tag)
+		if err != nil {
+			continue
+		}
+
+		latest[fmt.Sprintf("%d.%d", sv.Major(), sv.Minor())] = tag
 	}
 
-	tag := keys[len(keys)-1]
-	return tag, assets[tag], nil
+	return latest, nil
 }
 
-func (v *VersionsImpl) SortMapKeys(m map[string]string, decending bool) []string {
-	vs := []*semver.Version{}
-	for k, _ := range m {
-		v, _ := semver.NewVersion(k)
-		vs = append(vs, v)
+// GetEffectiveLatest returns the asset for the release which GitHub designates as the latest
+// provided that it satisfies the constraint and has a matching asset. When there is no
+// latest release or it does not match, the latest release by semantic version is returned
+func (v *VersionsImpl) GetEffectiveLatest(constraint string) (string, string, error) {
+	constraint = v.constraint(constraint)
+
+	if _, err := semver.NewConstraint(constraint); constraint != "" && err != nil {
+		return "", "", xerrors.Errorf("Invalid sematic version constraint: %w", err)
 	}
 
-	sort.Sort(semver.Collection(vs))
+	if v.options.Frozen {
+		return v.GetLatestReleaseURL(constraint)
+	}
 
-	versions := []string{}
+	r, _, err := v.client.Repositories.GetLatestRelease(context.Background(), v.options.Organization, v.options.Repo)
+	if err == nil && r.TagName != nil {
+		valid := true
+		if constraint != "" {
+			// a latest release which is not a semantic version, e.g. nightly, does not match
+			valid, _ = v.InRange(*r.TagName, constraint)
+		}
 
-	// return asccending order
-	if !decending {
-		for _, v := range vs {
-			versions = append(versions, v.Original())
+		if a := v.findAsset(*r.TagName, r.Assets); valid && a != nil {
+			return *r.TagName, *a.BrowserDownloadURL, nil
 		}
-		return versions
 	}
 
-	for i := len(vs) - 1; i >= 0; i-- {
-		versions = append(versions, vs[i].Original())
+	return v.GetLatestReleaseURL(constraint)
+}
+
+// GetLatestExcluding returns the asset for the latest release matching the constraint other than
+// excludeTag, allowing a self updater to select a version other than the one which is running.
+// excludeTag matches tags with or without the v prefix. Returns an empty tag when no other
+// release matches the constraint
+func (v *VersionsImpl) GetLatestExcluding(constraint, excludeTag string) (string, string, error) {
+	assets, err := v.ListReleases(constraint)
+	if err != nil {
+		return "", "", err
 	}
-	return versions
+
+	for _, tag := range v.SortMapKeys(assets, true) {
+		if v.NormalizeTag(tag) != v.NormalizeTag(excludeTag) {
+			return tag, assets[tag], nil
+		}
+	}
+
+	return "", "", nil
 }
 
-func (v *VersionsImpl) InRange(version string, constraint string) (bool, error) {
-	c, err := semver.NewConstraint(constraint)
+// GetAssetModTime returns the time the asset for the given release tag was last updated
+func (v *VersionsImpl) GetAssetModTime(tag string) (time.Time, error) {
+	if v.options.Frozen {
+		return time.Time{}, ErrFrozen
+	}
+
+	r, err := v.GetRawRelease(tag)
 	if err != nil {
-		return false, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+		return time.Time{}, err
+	}
+
+	a := v.findAsset(tag, r.Assets)
+	if a == nil {
+		return time.Time{}, xerrors.Errorf("Unable to find asset for release %s", tag)
+	}
+
+	if a.UpdatedAt == nil {
+		return time.Time{}, xerrors.Errorf("Asset for release %s does not have an updated time", tag)
+	}
+
+	return a.UpdatedAt.Time, nil
+}
+
+// DownloadRelease and uncompress the given release
+func (v *VersionsImpl) DownloadRelease(tag, url string) (filePath string, err error) {
+	return v.downloadRelease(tag, url, true)
+}
+
+// InstallFromURL downloads, uncompresses and verifies the asset at url and installs it as
+// the version tag in the same way as DownloadRelease, GitHub is not called so the commit for
+// the tag is not recorded in the metadata. Returns an error when tag is not a semantic version
+func (v *VersionsImpl) InstallFromURL(tag, url string) (string, error) {
+	if _, err := v.parseVersion(tag); err != nil {
+		return "", xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	return v.downloadRelease(tag, url, false)
+}
+
+// downloadRelease downloads and uncompresses the asset at url as the version tag, when
+// fromGitHub is set the commit for the tag is resolved from GitHub and recorded in the metadata
+func (v *VersionsImpl) downloadRelease(tag, url string, fromGitHub bool) (string, error) {
+	fp := v.exePath(tag)
+
+	if v.options.Frozen {
+		if _, err := os.Stat(fp); err != nil {
+			return "", ErrFrozen
+		}
+
+		return v.externalPath(fp), nil
+	}
+
+	fp = v.savedExePath(tag)
+
+	// the commit is resolved before the asset is downloaded so that a tag which is moved
+	// after the install is reported by VerifyReleaseCommit
+	var commit string
+	if fromGitHub {
+		var err error
+
+		commit, err = v.installCommit(tag)
+		if err != nil {
+			return "", err
+		}
 	}
 
-	ver, err := semver.NewVersion(version)
+	tmp, err := v.downloadDir()
 	if err != nil {
-		return false, xerrors.Errorf("Invalid sematic version: %w", err)
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	asset, name, err := v.cachedBlob(tag, url)
+	if err != nil {
+		return "", err
+	}
+
+	var d digests
+	if asset == "" {
+		asset, d, err = v.fetchWithProxy(context.Background(), tmp, tag, url)
+		if err != nil {
+			return "", xerrors.Errorf("Unable to download file: %w", err)
+		}
+
+		err = v.verify(tag, asset, d)
+		if err != nil {
+			if v.options.QuarantineOnFailure {
+				if qerr := v.quarantine(tag, url, asset, err); qerr != nil {
+					return "", qerr
+				}
+			}
+
+			return "", err
+		}
+
+		err = v.verifyMinisign(tmp, url, asset)
+		if err != nil {
+			if v.options.QuarantineOnFailure {
+				if qerr := v.quarantine(tag, url, asset, err); qerr != nil {
+					return "", qerr
+				}
+			} else {
+				os.Remove(asset)
+			}
+
+			return "", err
+		}
+
+		if v.options.ContentAddressedStore {
+			asset, err = v.storeBlob(asset, d)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	err = v.unpack(v.versionDir(tag), name, asset)
+	if err != nil {
+		return "", err
+	}
+
+	if v.options.SelectLargestExecutable {
+		err = selectLargestExecutable(v.versionDir(tag), v.exeName(tag))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if v.options.SavedBinaryName != "" {
+		err = os.Rename(path.Join(v.versionDir(tag), v.exeName(tag)), fp)
+		if err != nil {
+			return "", xerrors.Errorf("Unable to rename executable: %w", err)
+		}
+	}
+
+	err = v.probeVersion(tag, fp)
+	if err != nil {
+		os.RemoveAll(v.versionDir(tag))
+		return "", err
+	}
+
+	if v.options.CleanExtras {
+		err = cleanExtras(v.versionDir(tag), v.keepFiles(tag, fp))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// raw binaries are installed as downloaded so the digest computed during the
+	// download is also the checksum of the executable
+	if archiveExt(name) != "" || v.options.SelectLargestExecutable {
+		d = nil
+	}
+
+	sum, err := d.sum("sha256", fp)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to read downloaded file: %w", err)
+	}
+
+	m := Metadata{
+		Tag:         tag,
+		URL:         url,
+		SHA256:      hex.EncodeToString(sum),
+		InstalledAt: time.Now(),
+		Commit:      commit,
+	}
+
+	err = v.metadataStore().Set(m)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to record metadata: %w", err)
+	}
+
+	if v.options.SetCurrentOnInstall {
+		err = v.setCurrentIfHighest(tag)
+		if err != nil {
+			return v.externalPath(fp), xerrors.Errorf("Installed %s but unable to set it as the current version: %w", tag, err)
+		}
+	}
+
+	return v.externalPath(fp), nil
+}
+
+// probeVersion compares the version returned from VersionProbeFunc for the executable
+// at fp with the tag, returns ErrVersionMismatch when they are not the same version
+func (v *VersionsImpl) probeVersion(tag, fp string) error {
+	if v.options.VersionProbeFunc == nil {
+		return nil
+	}
+
+	probed, err := v.options.VersionProbeFunc(fp)
+	if err != nil {
+		return xerrors.Errorf("Unable to probe version of %s: %w", fp, err)
+	}
+
+	pv, perr := v.parseVersion(probed)
+	tv, terr := v.parseVersion(tag)
+	if perr == nil && terr == nil && pv.Equal(tv) && pv.Metadata() == tv.Metadata() {
+		return nil
+	}
+
+	if perr != nil && v.NormalizeTag(probed) == v.NormalizeTag(tag) {
+		return nil
+	}
+
+	return xerrors.Errorf("%w: release %s, executable reports %s", ErrVersionMismatch, tag, probed)
+}
+
+// installLatest downloads the latest release matching the constraint unless it is already
+// installed, returns ErrNoMatchingRelease when no release matches the constraint
+func (v *VersionsImpl) installLatest(constraint string) (string, string, error) {
+	tag, url, err := v.GetLatestReleaseURL(constraint)
+	if err != nil {
+		return "", "", err
+	}
+
+	if tag == "" {
+		return "", "", xerrors.Errorf("%w: %s", ErrNoMatchingRelease, constraint)
+	}
+
+	fp := v.exePath(tag)
+	if _, err := os.Stat(fp); err != nil {
+		_, err = v.DownloadRelease(tag, url)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return tag, v.externalPath(fp), nil
+}
+
+// DownloadLatestN downloads the latest n releases which match the constraint, downloads
+// run in parallel bounded by Concurrency and versions which are already installed are skipped.
+// Returns the path of each installed version and any errors keyed by tag, an error
+// listing the releases or installed versions is returned with an empty tag.
+// Nothing is downloaded when n is 0 and an error is returned when n is negative
+func (v *VersionsImpl) DownloadLatestN(constraint string, n int) (map[string]string, map[string]error) {
+	if n < 0 {
+		return map[string]string{}, map[string]error{"": xerrors.Errorf("Invalid number of releases %d, must not be negative", n)}
+	}
+
+	if n == 0 {
+		return map[string]string{}, map[string]error{}
+	}
+
+	releases, err := v.ListReleases(constraint)
+	if err != nil {
+		return map[string]string{}, map[string]error{"": err}
+	}
+
+	installed, err := v.installedVersions("")
+	if err != nil {
+		return map[string]string{}, map[string]error{"": err}
+	}
+
+	paths := map[string]string{}
+	download := map[string]string{}
+
+	for _, tag := range v.SortMapKeys(releases, true) {
+		if len(paths)+len(download) == n {
+			break
+		}
+
+		if p, ok := installed[tag]; ok {
+			paths[tag] = p
+			continue
+		}
+
+		download[tag] = releases[tag]
+	}
+
+	dl, errs := v.downloadAll(download)
+	for tag, p := range dl {
+		paths[tag] = p
+	}
+
+	return paths, errs
+}
+
+// ResumeBatch installs the given release tags skipping versions which are already installed
+// and pass VerifyIntegrity, so that an interrupted batch install can be resumed. Versions
+// which are installed but fail verification are downloaded again. Downloads run in parallel
+// bounded by Concurrency. Returns the path of each installed version and any errors keyed
+// by tag, an error listing the releases is returned with an empty tag
+func (v *VersionsImpl) ResumeBatch(tags []string) (map[string]string, map[string]error) {
+	paths := map[string]string{}
+	missing := []string{}
+
+	for _, tag := range tags {
+		if v.VerifyIntegrity(tag) == nil {
+			paths[tag] = v.externalPath(v.exePath(tag))
+			continue
+		}
+
+		missing = append(missing, tag)
+	}
+
+	if len(missing) == 0 {
+		return paths, map[string]error{}
+	}
+
+	releases, err := v.cachedReleases("")
+	if err != nil {
+		return paths, map[string]error{"": err}
+	}
+
+	errs := map[string]error{}
+	download := map[string]string{}
+
+	for _, tag := range missing {
+		url, ok := releases[tag]
+		if !ok {
+			errs[tag] = xerrors.Errorf("%w: %s", ErrNoMatchingRelease, tag)
+			continue
+		}
+
+		download[tag] = url
+	}
+
+	dl, dlErrs := v.downloadAll(download)
+	for tag, p := range dl {
+		paths[tag] = p
+	}
+
+	for tag, err := range dlErrs {
+		errs[tag] = err
+	}
+
+	return paths, errs
+}
+
+// ListInstalledVersions lists the versions of the software which are installed int the archive folder
+func (v *VersionsImpl) ListInstalledVersions(constraint string) (map[string]string, error) {
+	return v.installedVersions(v.constraint(constraint))
+}
+
+// installedVersions is ListInstalledVersions without reading the constraint from ConstraintEnvVar
+func (v *VersionsImpl) installedVersions(constraint string) (map[string]string, error) {
+	installed, err := v.listInstalledVersions(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	for tag, fp := range installed {
+		installed[tag] = v.externalPath(fp)
+	}
+
+	return installed, nil
+}
+
+// listInstalledVersions returns the installed versions matching the constraint with the
+// path of the executable in ReleasesPath regardless of RelativePaths, an empty constraint
+// always lists every installed version
+func (v *VersionsImpl) listInstalledVersions(constraint string) (map[string]string, error) {
+	versions := map[string]string{}
+
+	// list folders at the archive loacation matching the semver
+	files, err := ioutil.ReadDir(v.installRoot())
+	// the releases path does not exist until the first version is installed
+	if os.IsNotExist(err) {
+		return versions, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to list releases: %w", err)
+	}
+
+	for _, f := range files {
+		// skip files and folders which are not versions such as metadata indexes
+		if !f.IsDir() {
+			continue
+		}
+
+		if _, err := v.parseVersion(f.Name()); err != nil {
+			continue
+		}
+
+		if constraint != "" {
+			valid, err := v.InRange(f.Name(), constraint)
+			// if the tag does not match continue
+			if err != nil || !valid {
+				continue
+			}
+		}
+
+		versions[f.Name()] = v.exePath(f.Name())
+	}
+
+	return versions, nil
+}
+
+func (v *VersionsImpl) GetInstalledVersion(constraint string) (string, string, error) {
+	return v.installedVersion(v.constraint(constraint))
+}
+
+// installedVersion is GetInstalledVersion without reading the constraint from ConstraintEnvVar
+func (v *VersionsImpl) installedVersion(constraint string) (string, string, error) {
+	assets, err := v.installedVersions(constraint)
+	if err != nil {
+		return "", "", err
+	}
+
+	keys := v.SortMapKeys(assets, false)
+
+	if len(keys) == 0 {
+		return "", "", nil
+	}
+
+	tag := keys[len(keys)-1]
+	return tag, assets[tag], nil
+}
+
+// InstalledSatisfies returns true and the tag of the highest installed version when an installed
+// version matches the constraint, GitHub is not called. Returns an error when the constraint is invalid
+func (v *VersionsImpl) InstalledSatisfies(constraint string) (bool, string, error) {
+	constraint = v.constraint(constraint)
+
+	if _, err := semver.NewConstraint(constraint); constraint != "" && err != nil {
+		return false, "", xerrors.Errorf("Invalid sematic version constraint: %w", err)
+	}
+
+	tag, _, err := v.GetInstalledVersion(constraint)
+	if err != nil {
+		return false, "", err
+	}
+
+	return tag != "", tag, nil
+}
+
+// InstalledPath returns the path of the executable installed for the exact tag, the tag is
+// matched with or without the v prefix. Unlike GetInstalledVersion no constraint is applied.
+// Returns ErrNotInstalled when the executable for the tag does not exist
+func (v *VersionsImpl) InstalledPath(tag string) (string, error) {
+	for _, t := range []string{tag, v.DenormalizeTag(tag), v.NormalizeTag(tag)} {
+		fp := v.exePath(t)
+
+		if _, err := os.Stat(fp); err == nil {
+			return v.externalPath(fp), nil
+		}
+	}
+
+	return "", xerrors.Errorf("%w: %s", ErrNotInstalled, tag)
+}
+
+// GetInstalledPathIgnoringBuild returns the path of the installed version which has the
+// same version as the given version ignoring build metadata and any v prefix, e.g. 1.2.3
+// returns the version installed as v1.2.3+build.5. When several builds of the version
+// are installed the path of the highest tag is returned.
+// Returns ErrNotInstalled when the version is not installed
+func (v *VersionsImpl) GetInstalledPathIgnoringBuild(version string) (string, error) {
+	sv, err := v.parseVersion(version)
+	if err != nil {
+		return "", xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	installed, err := v.installedVersions("")
+	if err != nil {
+		return "", err
+	}
+
+	for _, tag := range v.SortMapKeys(installed, true) {
+		tv, err := v.parseVersion(tag)
+		if err != nil {
+			continue
+		}
+
+		// Equal compares precedence which ignores build metadata
+		if tv.Equal(sv) {
+			return installed[tag], nil
+		}
+	}
+
+	return "", ErrNotInstalled
+}
+
+// UpgradeWithinMajor finds the latest release which does not cross the major version
+// boundary of the latest installed version.
+// Returns ErrNotInstalled when there are no installed versions
+func (v *VersionsImpl) UpgradeWithinMajor() (string, string, bool, error) {
+	current, _, err := v.installedVersion("")
+	if err != nil {
+		return "", "", false, err
+	}
+
+	if current == "" {
+		return "", "", false, ErrNotInstalled
+	}
+
+	cv, err := v.parseVersion(current)
+	if err != nil {
+		return "", "", false, xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	constraint := fmt.Sprintf(">= %d.0.0, < %d.0.0", cv.Major(), cv.Major()+1)
+	tag, url, err := v.GetLatestReleaseURL(constraint)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	if tag == "" {
+		return "", "", false, nil
+	}
+
+	lv, err := v.parseVersion(tag)
+	if err != nil {
+		return "", "", false, xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	return tag, url, lv.GreaterThan(cv), nil
+}
+
+// BumpKind is the semantic version component incremented by NextVersion
+type BumpKind string
+
+const (
+	// BumpMajor increments the major version, resetting the minor and patch versions
+	BumpMajor BumpKind = "major"
+	// BumpMinor increments the minor version, resetting the patch version
+	BumpMinor BumpKind = "minor"
+	// BumpPatch increments the patch version
+	BumpPatch BumpKind = "patch"
+)
+
+// NextReleaseVersion returns the version which follows the latest release when applying
+// the given bump, e.g. the latest release 1.4.2 with the bump BumpMinor returns 1.5.0
+func (v *VersionsImpl) NextReleaseVersion(bump BumpKind) (string, error) {
+	tag, _, err := v.latestReleaseURL("")
+	if err != nil {
+		return "", err
+	}
+
+	if tag == "" {
+		return "", xerrors.Errorf("Unable to find latest release")
+	}
+
+	return v.NextVersion(tag, bump)
+}
+
+// NextVersion returns the version which follows current when applying the given bump,
+// lower components are reset and any pre-release or build metadata is removed.
+// Following semver, the patch bump of a pre-release is the release of the same version
+// e.g. 1.4.3-beta.1 with the bump BumpPatch returns 1.4.3
+func (v *VersionsImpl) NextVersion(current string, bump BumpKind) (string, error) {
+	cv, err := v.parseVersion(current)
+	if err != nil {
+		return "", xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	var next semver.Version
+	switch bump {
+	case BumpMajor:
+		next = cv.IncMajor()
+	case BumpMinor:
+		next = cv.IncMinor()
+	case BumpPatch:
+		next = cv.IncPatch()
+	default:
+		return "", xerrors.Errorf("Invalid bump %s, expected major, minor or patch", bump)
+	}
+
+	if p := v.releasePrefix(); p != tagPrefix && strings.HasPrefix(current, p) {
+		return v.DenormalizeTag(next.Original()), nil
+	}
+
+	return next.Original(), nil
+}
+
+// IsBreakingUpgrade returns true when the major version of to is greater than the
+// major version of from. Following semver, while the major version is 0 an increase
+// in the minor version is also a breaking change
+func (v *VersionsImpl) IsBreakingUpgrade(from, to string) (bool, error) {
+	fv, err := v.parseVersion(from)
+	if err != nil {
+		return false, xerrors.Errorf("Invalid sematic version %s: %w", from, err)
+	}
+
+	tv, err := v.parseVersion(to)
+	if err != nil {
+		return false, xerrors.Errorf("Invalid sematic version %s: %w", to, err)
+	}
+
+	if tv.Major() > fv.Major() {
+		return true, nil
+	}
+
+	if fv.Major() == 0 && tv.Major() == 0 && tv.Minor() > fv.Minor() {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// WhichInstalled returns the tag of the installed version whose executable is
+// at the given path, this allows a tool to determine which managed version is running.
+// Symbolic links are followed and the active executable in ReleasesPath/bin returns
+// the current version
+func (v *VersionsImpl) WhichInstalled(exePath string) (string, error) {
+	fp, err := realPath(exePath)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := realPath(v.options.ReleasesPath)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, fp)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", ErrNotManaged
+	}
+
+	// the active executable is copied or linked from the current version
+	if fp == filepath.Join(root, activeFolder, v.activeName()) {
+		current, err := v.GetCurrent()
+		if err != nil {
+			return "", err
+		}
+
+		if current == "" {
+			return "", ErrNotManaged
+		}
+
+		return current, nil
+	}
+
+	installed, err := v.listInstalledVersions("")
+	if err != nil {
+		return "", err
+	}
+
+	for tag, p := range installed {
+		ip, err := realPath(p)
+		if err != nil {
+			continue
+		}
+
+		if ip == fp {
+			return tag, nil
+		}
+	}
+
+	return "", ErrNotManaged
+}
+
+// realPath returns the absolute path of fp with any symbolic links resolved, paths which
+// do not exist are returned without resolving links
+func realPath(fp string) (string, error) {
+	abs, err := filepath.Abs(fp)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to determine absolute path: %w", err)
+	}
+
+	if r, err := filepath.EvalSymlinks(abs); err == nil {
+		return r, nil
+	}
+
+	return abs, nil
+}
+
+// InstalledStats returns the number of installed versions and the tags of the highest and
+// lowest installed versions, the tags are empty when no versions are installed
+func (v *VersionsImpl) InstalledStats() (int, string, string, error) {
+	installed, err := v.installedVersions("")
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	if len(installed) == 0 {
+		return 0, "", "", nil
+	}
+
+	keys := v.SortMapKeys(installed, false)
+
+	return len(keys), keys[len(keys)-1], keys[0], nil
+}
+
+// ListWithInstallState returns the releases matching the constraint newest first, marking
+// the releases which are installed
+func (v *VersionsImpl) ListWithInstallState(constraint string) ([]VersionState, error) {
+	constraint = v.constraint(constraint)
+
+	available, err := v.cachedReleases(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := v.listInstalledVersions(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []VersionState{}
+	for _, tag := range v.SortMapKeys(available, true) {
+		_, ok := installed[tag]
+		states = append(states, VersionState{Tag: tag, URL: available[tag], Installed: ok})
+	}
+
+	return states, nil
+}
+
+// InstalledVersionsSorted returns the installed versions in semantic version order
+// along with the size of the executable and the install time recorded in the metadata,
+// falling back to the modification time of the executable. Versions whose executable can
+// not be read, such as partially removed installs, are skipped
+func (v *VersionsImpl) InstalledVersionsSorted(descending bool) ([]InstalledVersion, error) {
+	installed, err := v.listInstalledVersions("")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []InstalledVersion{}
+	for _, tag := range v.SortMapKeys(installed, descending) {
+		fi, err := os.Stat(installed[tag])
+		if err != nil {
+			continue
+		}
+
+		installedAt, err := v.installTime(tag, installed[tag])
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, InstalledVersion{
+			Tag:         tag,
+			Path:        v.externalPath(installed[tag]),
+			Size:        fi.Size(),
+			InstallTime: installedAt,
+		})
+	}
+
+	return versions, nil
+}
+
+// StaleInstalled returns the tags of the installed versions matching the constraint which are
+// older than the latest available release matching the constraint, sorted in ascending order
+func (v *VersionsImpl) StaleInstalled(constraint string) ([]string, error) {
+	latest, _, err := v.GetLatestReleaseURL(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := []string{}
+	if latest == "" {
+		return stale, nil
+	}
+
+	lv, err := v.parseVersion(latest)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	installed, err := v.ListInstalledVersions(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tag := range v.SortMapKeys(installed, false) {
+		iv, err := v.parseVersion(tag)
+		if err != nil {
+			continue
+		}
+
+		if iv.LessThan(lv) {
+			stale = append(stale, tag)
+		}
+	}
+
+	return stale, nil
+}
+
+// InstalledOlderThan returns the tags of the installed versions which were installed more
+// than d ago sorted in ascending order. The install time is read from the metadata recorded
+// at install, or the modification time of the executable when there is no metadata.
+// The current version is never returned
+func (v *VersionsImpl) InstalledOlderThan(d time.Duration) ([]string, error) {
+	installed, err := v.listInstalledVersions("")
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := v.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-d)
+
+	old := []string{}
+	for _, tag := range v.SortMapKeys(installed, false) {
+		if tag == current {
+			continue
+		}
+
+		installedAt, err := v.installTime(tag, installed[tag])
+		if err != nil {
+			return nil, err
+		}
+
+		if installedAt.Before(cutoff) {
+			old = append(old, tag)
+		}
+	}
+
+	return old, nil
+}
+
+// installTime returns the time the version was installed from its metadata falling
+// back to the modification time of the executable
+func (v *VersionsImpl) installTime(tag, exe string) (time.Time, error) {
+	m, err := v.metadataStore().Get(tag)
+	if err == nil && !m.InstalledAt.IsZero() {
+		return m.InstalledAt, nil
+	}
+
+	fi, err := os.Stat(exe)
+	if err != nil {
+		return time.Time{}, xerrors.Errorf("Unable to read installed version %s: %w", tag, err)
+	}
+
+	return fi.ModTime(), nil
+}
+
+func (v *VersionsImpl) SortMapKeys(m map[string]string, decending bool) []string {
+	vs := []*semver.Version{}
+	// tags holds the tag for each version as the version parsed from a Go
+	// toolchain tag does not include the go prefix
+	tags := map[*semver.Version]string{}
+	for k, _ := range m {
+		// keys which are not semantic versions can not be ordered and are dropped
+		sv, err := v.parseVersion(k)
+		if err != nil {
+			continue
+		}
+
+		vs = append(vs, sv)
+		tags[sv] = k
+	}
+
+	// semantic versions which differ only by build metadata have the same precedence,
+	// order these by the original tag so that the result is deterministic and the
+	// lexicographically highest tag is treated as the latest
+	sort.Slice(vs, func(i, j int) bool {
+		if c := vs[i].Compare(vs[j]); c != 0 {
+			return c < 0
+		}
+
+		return tags[vs[i]] < tags[vs[j]]
+	})
+
+	versions := []string{}
+
+	// return asccending order
+	if !decending {
+		for _, sv := range vs {
+			versions = append(versions, tags[sv])
+		}
+		return versions
+	}
+
+	for i := len(vs) - 1; i >= 0; i-- {
+		versions = append(versions, tags[vs[i]])
+	}
+	return versions
+}
+
+func (v *VersionsImpl) InRange(version string, constraint string) (bool, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+	}
+
+	ver, err := v.parseVersion(version)
+	if err != nil {
+		return false, xerrors.Errorf("Invalid sematic version: %w", err)
+	}
+
+	if c.Check(ver) {
+		return true, nil
+	}
+
+	// semantic version constraints only match pre-releases when the constraint contains a
+	// pre-release, when IncludePrerelease is set match pre-releases of versions in range
+	if v.options.IncludePrerelease && ver.Prerelease() != "" {
+		return c.Check(releaseVersion(ver)), nil
+	}
+
+	return false, nil
+}
+
+// releaseVersion returns the version without any pre-release or build metadata
+func releaseVersion(ver *semver.Version) *semver.Version {
+	rv, _ := semver.NewVersion(fmt.Sprintf("%d.%d.%d", ver.Major(), ver.Minor(), ver.Patch()))
+	return rv
+}
+
+// ListOrgRepos returns the names of the repositories in the given GitHub organization
+// this allows tools which manage many projects to create a Versions for each repository.
+// Returns ErrFrozen when Frozen is set
+func (v *VersionsImpl) ListOrgRepos(org string) ([]string, error) {
+	if v.options.Frozen {
+		return nil, ErrFrozen
+	}
+
+	repos := []string{}
+	opts := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		gr, resp, err := v.client.Repositories.ListByOrg(context.Background(), org, opts)
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to list Github repositories: %w", err)
+		}
+
+		for _, r := range gr {
+			repos = append(repos, *r.Name)
+		}
+
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// listGitHubReleases returns the releases for the repository from GitHub, fetching every
+// page unless MaxReleases is set
+func (v *VersionsImpl) listGitHubReleases() ([]*github.RepositoryRelease, error) {
+	return v.listGitHubReleasesContext(context.Background())
+}
+
+// listGitHubReleasesContext is listGitHubReleases using the given context for the requests
+func (v *VersionsImpl) listGitHubReleasesContext(ctx context.Context) ([]*github.RepositoryRelease, error) {
+	releases := []*github.RepositoryRelease{}
+	opts := &github.ListOptions{PerPage: 100}
+	if v.options.MaxReleases > 0 && v.options.MaxReleases < opts.PerPage {
+		opts.PerPage = v.options.MaxReleases
+	}
+
+	for {
+		var gr []*github.RepositoryRelease
+		var resp *github.Response
+
+		err := v.retry(ctx, func() (*github.Response, error) {
+			var err error
+
+			gr, resp, err = v.client.Repositories.ListReleases(ctx, v.options.Organization, v.options.Repo, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to list Github releases: %w", err)
+		}
+
+		releases = append(releases, gr...)
+
+		// GitHub lists releases newest first so stopping early returns the most recent releases
+		if v.options.MaxReleases > 0 && len(releases) >= v.options.MaxReleases {
+			return releases[:v.options.MaxReleases], nil
+		}
+
+		if resp.NextPage == 0 {
+			return releases, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// findAsset returns the asset matching the name returned from AssetNameFunc
+// returns nil when no asset matches or the release does not contain the assets
+// returned from RequiredAuxAssetsFunc
+func (v *VersionsImpl) findAsset(tag string, assets []github.ReleaseAsset) *github.ReleaseAsset {
+	ver := v.NormalizeTag(tag)
+
+	if !v.hasAuxAssets(ver, assets) {
+		return nil
+	}
+
+	for _, goarch := range v.archNames() {
+		for _, goos := range v.osNames() {
+			mappedOS, mappedArch := v.mapPlatform(goos, goarch)
+			fn := v.options.AssetNameFunc(ver, mappedOS, mappedArch)
+
+			if a := v.selectAsset(fn, assets); a != nil {
+				return a
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasAuxAssets returns true when the assets contain every asset named by RequiredAuxAssetsFunc
+func (v *VersionsImpl) hasAuxAssets(ver string, assets []github.ReleaseAsset) bool {
+	if v.options.RequiredAuxAssetsFunc == nil {
+		return true
+	}
+
+	names := map[string]bool{}
+	for _, a := range assets {
+		names[normalizeAssetName(a.GetName())] = true
+	}
+
+	for _, n := range v.options.RequiredAuxAssetsFunc(ver) {
+		if !names[normalizeAssetName(n)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectAsset returns the asset matching the name fn, an asset matches when its name
+// is equal to fn or differs only by an archive extension. When a release contains both
+// a raw binary and an archive, the archive is returned when PreferArchive is set,
+// otherwise an exact match to fn is returned followed by the raw binary
+func (v *VersionsImpl) selectAsset(fn string, assets []github.ReleaseAsset) *github.ReleaseAsset {
+	fn = normalizeAssetName(fn)
+	base := trimArchiveExt(fn)
+
+	var exact, raw, archive *github.ReleaseAsset
+	for i, a := range assets {
+		name := normalizeAssetName(*a.Name)
+		if trimArchiveExt(name) != base {
+			continue
+		}
+
+		if name == fn {
+			exact = &assets[i]
+		}
+
+		if archiveExt(name) == "" {
+			raw = &assets[i]
+			continue
+		}
+
+		// when there are multiple archive formats select the first by name so
+		// that the result does not depend on the order of the assets
+		if archive == nil || name < normalizeAssetName(*archive.Name) {
+			archive = &assets[i]
+		}
+	}
+
+	if v.options.PreferArchive {
+		if exact != nil && archiveExt(fn) != "" {
+			return exact
+		}
+
+		if archive != nil {
+			return archive
+		}
+	}
+
+	if exact != nil {
+		return exact
+	}
+
+	if raw != nil {
+		return raw
+	}
+
+	return archive
+}
+
+// normalizeAssetName returns the lower case, unescaped asset name so that names
+// containing spaces or other escaped characters compare equal however they are encoded
+func normalizeAssetName(name string) string {
+	if n, err := url.PathUnescape(name); err == nil {
+		name = n
+	}
+
+	return strings.ToLower(name)
+}
+
+// osNames returns the names to try for GOOS when matching assets
+// GOOS is always first followed by any synonyms when UseOSSynonyms is set
+func (v *VersionsImpl) osNames() []string {
+	names := []string{v.options.GOOS}
+	if v.options.UseOSSynonyms {
+		names = append(names, osSynonyms[v.options.GOOS]...)
+	}
+
+	return names
+}
+
+// mapPlatform returns the GOOS and GOARCH mapped with PlatformMapFunc, when
+// PlatformMapFunc is not set they are returned unchanged
+func (v *VersionsImpl) mapPlatform(goos, goarch string) (string, string) {
+	if v.options.PlatformMapFunc == nil {
+		return goos, goarch
+	}
+
+	return v.options.PlatformMapFunc(goos, goarch)
+}
+
+// archNames returns the architectures to try when matching assets, the native
+// architecture detected with DetectNativeArch is tried before GOARCH
+func (v *VersionsImpl) archNames() []string {
+	if v.nativeArch != "" {
+		return []string{v.nativeArch, v.options.GOARCH}
+	}
+
+	return []string{v.options.GOARCH}
+}
+
+// metadataStore returns the MetadataStore from the options or a SidecarMetadataStore
+// for the current layout when one has not been set
+func (v *VersionsImpl) metadataStore() MetadataStore {
+	if v.options.MetadataStore != nil {
+		return v.options.MetadataStore
+	}
+
+	return NewSidecarMetadataStore(v.installRoot())
+}
+
+// exePath returns the path of the executable for the given tag in ReleasesPath, versions
+// installed before SavedBinaryName was set are found with the name from ExeNameFunc
+func (v *VersionsImpl) exePath(tag string) string {
+	fp := v.savedExePath(tag)
+	if v.options.SavedBinaryName == "" {
+		return fp
+	}
+
+	if _, err := os.Stat(fp); os.IsNotExist(err) {
+		exe := path.Join(v.versionDir(tag), v.exeName(tag))
+		if _, err := os.Stat(exe); err == nil {
+			return exe
+		}
+	}
+
+	return fp
+}
+
+// savedExePath returns the path the executable for the given tag is saved to when the
+// version is installed, SavedBinaryName when set otherwise the name from ExeNameFunc
+func (v *VersionsImpl) savedExePath(tag string) string {
+	if v.options.SavedBinaryName != "" {
+		return path.Join(v.versionDir(tag), v.options.SavedBinaryName)
+	}
+
+	return path.Join(v.versionDir(tag), v.exeName(tag))
+}
+
+// externalPath returns the path returned to callers for a path in ReleasesPath, when
+// RelativePaths is set the path is relative to ReleasesPath
+func (v *VersionsImpl) externalPath(fp string) string {
+	if !v.options.RelativePaths {
+		return fp
+	}
+
+	rel, err := filepath.Rel(v.options.ReleasesPath, fp)
+	if err != nil {
+		return fp
+	}
+
+	return rel
+}
+
+// versionDir returns the folder the version with the given tag is installed to
+func (v *VersionsImpl) versionDir(tag string) string {
+	return path.Join(v.installRoot(), tag)
+}
+
+// exeName returns the name of the executable in the asset for the given tag
+func (v *VersionsImpl) exeName(tag string) string {
+	ver := v.NormalizeTag(tag)
+
+	return v.options.ExeNameFunc(ver, v.options.GOOS, v.options.GOARCH)
+}
+
+// listFrozen returns the installed versions matching the constraint in place of
+// the GitHub releases, the value for each version is the path of the executable
+func (v *VersionsImpl) listFrozen(constraint string) (map[string]string, error) {
+	installed, err := v.listInstalledVersions(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(installed) == 0 {
+		return nil, ErrFrozen
+	}
+
+	return installed, nil
+}
+
+// sha256File returns the hex encoded sha256 checksum of the file at the given path
+func sha256File(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// constraint returns the given constraint, when the constraint is empty and
+// ConstraintEnvVar has been set the value of the environment variable is returned
+func (v *VersionsImpl) constraint(c string) string {
+	if c != "" || v.options.ConstraintEnvVar == "" {
+		return c
 	}
 
-	return c.Check(ver), nil
+	return os.Getenv(v.options.ConstraintEnvVar)
 }
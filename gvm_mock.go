@@ -1,6 +1,11 @@
 package gvm
 
 import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/google/go-github/github"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -18,18 +23,299 @@ func (m *MockVersions) ListReleases(constraint string) (map[string]string, error
 	return nil, args.Error(1)
 }
 
+func (m *MockVersions) ListReleasesWithAssetFunc(constraint string, fn func(ver, goos, goarch string) string) (map[string]string, error) {
+	args := m.Called(constraint, fn)
+
+	if ma, ok := args.Get(0).(map[string]string); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) ListVerifiableReleases(constraint string) (map[string]string, error) {
+	args := m.Called(constraint)
+
+	if ma, ok := args.Get(0).(map[string]string); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) GetRawRelease(tag string) (*github.RepositoryRelease, error) {
+	args := m.Called(tag)
+
+	if r, ok := args.Get(0).(*github.RepositoryRelease); ok {
+		return r, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) Warm(ctx context.Context, constraints ...string) error {
+	args := m.Called(ctx, constraints)
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) StartBackgroundRefresh(ctx context.Context, constraint string, interval time.Duration) {
+	m.Called(ctx, constraint, interval)
+}
+
+func (m *MockVersions) GetReleaseURLChecked(tag, constraint string) (string, error) {
+	args := m.Called(tag, constraint)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) GetReleaseByID(id int64) (Release, error) {
+	args := m.Called(id)
+
+	r, _ := args.Get(0).(Release)
+
+	return r, args.Error(1)
+}
+
+func (m *MockVersions) DownloadAssetByID(releaseID, assetID int64) (string, error) {
+	args := m.Called(releaseID, assetID)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) FindAssets(tag string, match AssetMatchFunc) ([]Asset, error) {
+	args := m.Called(tag, match)
+
+	if a, ok := args.Get(0).([]Asset); ok {
+		return a, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) DownloadAssetMatching(tag string, match AssetMatchFunc) (string, error) {
+	args := m.Called(tag, match)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) DownloadReleaseAssets(tag, glob, destDir string) (map[string]string, error) {
+	args := m.Called(tag, glob, destDir)
+
+	if ma, ok := args.Get(0).(map[string]string); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) DiffAssets(tagA, tagB string) ([]string, []string, error) {
+	args := m.Called(tagA, tagB)
+
+	added, _ := args.Get(0).([]string)
+	removed, _ := args.Get(1).([]string)
+
+	return added, removed, args.Error(2)
+}
+
+func (m *MockVersions) ListReleasesSince(tag string) ([]Release, error) {
+	args := m.Called(tag)
+
+	if r, ok := args.Get(0).([]Release); ok {
+		return r, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) CompleteVersions(prefix string) ([]string, error) {
+	args := m.Called(prefix)
+
+	if tags, ok := args.Get(0).([]string); ok {
+		return tags, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 func (m *MockVersions) GetLatestReleaseURL(constraint string) (tag string, url string, err error) {
 	args := m.Called(constraint)
 
 	return args.String(0), args.String(1), args.Error(2)
 }
 
+func (m *MockVersions) LoadPresets(data []byte) error {
+	args := m.Called(data)
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) GetLatestForPreset(name string) (tag, url string, err error) {
+	args := m.Called(name)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) ResolveAll(constraints []string) (tag, url string, err error) {
+	args := m.Called(constraints)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) Explain(constraint string) (string, error) {
+	args := m.Called(constraint)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) ExplainReleases(constraint string) ([]ReleaseDecision, error) {
+	args := m.Called(constraint)
+
+	if d, ok := args.Get(0).([]ReleaseDecision); ok {
+		return d, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) SuggestVersions(constraint string) ([]string, error) {
+	args := m.Called(constraint)
+
+	if tags, ok := args.Get(0).([]string); ok {
+		return tags, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) ResolveFromDir(dir string) (string, error) {
+	args := m.Called(dir)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) LatestPerMinor(constraint string) (map[string]string, error) {
+	args := m.Called(constraint)
+
+	if ma, ok := args.Get(0).(map[string]string); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) GetEffectiveLatest(constraint string) (tag string, url string, err error) {
+	args := m.Called(constraint)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) GetLatestExcluding(constraint, excludeTag string) (tag string, url string, err error) {
+	args := m.Called(constraint, excludeTag)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) GetTaggedRelease(gitTag string) (tag string, url string, err error) {
+	args := m.Called(gitTag)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) GetLatestStable(constraint string, minAge time.Duration) (tag string, url string, err error) {
+	args := m.Called(constraint, minAge)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) GetAssetModTime(tag string) (time.Time, error) {
+	args := m.Called(tag)
+
+	if t, ok := args.Get(0).(time.Time); ok {
+		return t, args.Error(1)
+	}
+
+	return time.Time{}, args.Error(1)
+}
+
 func (m *MockVersions) DownloadRelease(tag, url string) (path string, err error) {
 	args := m.Called(tag, url)
 
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockVersions) InstallEphemeral(tag, url string) (string, func() error, error) {
+	args := m.Called(tag, url)
+
+	cleanup, _ := args.Get(1).(func() error)
+
+	return args.String(0), cleanup, args.Error(2)
+}
+
+func (m *MockVersions) InstallFromURL(tag, url string) (string, error) {
+	args := m.Called(tag, url)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) DownloadReleaseForTarget(tag, url, goos, goarch string) (string, error) {
+	args := m.Called(tag, url, goos, goarch)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) DownloadReleaseToWriter(ctx context.Context, url string, w io.Writer) error {
+	args := m.Called(ctx, url, w)
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) InstallLatestAndPrune(constraint string, keep int) (string, []string, error) {
+	args := m.Called(constraint, keep)
+
+	if tags, ok := args.Get(1).([]string); ok {
+		return args.String(0), tags, args.Error(2)
+	}
+
+	return args.String(0), nil, args.Error(2)
+}
+
+func (m *MockVersions) InstallAs(label, constraint string) (tag, path string, err error) {
+	args := m.Called(label, constraint)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) GetByLabel(label string) (tag, path string, err error) {
+	args := m.Called(label)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) CleanupTemp() (int, error) {
+	args := m.Called()
+
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockVersions) DownloadLatestN(constraint string, n int) (map[string]string, map[string]error) {
+	args := m.Called(constraint, n)
+
+	paths, _ := args.Get(0).(map[string]string)
+	errs, _ := args.Get(1).(map[string]error)
+
+	return paths, errs
+}
+
+func (m *MockVersions) ResumeBatch(tags []string) (map[string]string, map[string]error) {
+	args := m.Called(tags)
+
+	paths, _ := args.Get(0).(map[string]string)
+	errs, _ := args.Get(1).(map[string]error)
+
+	return paths, errs
+}
+
 func (m *MockVersions) ListInstalledVersions(constraint string) (map[string]string, error) {
 	args := m.Called(constraint)
 
@@ -46,6 +332,148 @@ func (m *MockVersions) GetInstalledVersion(constraint string) (tag string, path
 	return args.String(0), args.String(1), args.Error(2)
 }
 
+func (m *MockVersions) InstalledSatisfies(constraint string) (bool, string, error) {
+	args := m.Called(constraint)
+
+	return args.Bool(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) NormalizeTag(tag string) string {
+	args := m.Called(tag)
+
+	return args.String(0)
+}
+
+func (m *MockVersions) DenormalizeTag(version string) string {
+	args := m.Called(version)
+
+	return args.String(0)
+}
+
+func (m *MockVersions) InstalledPath(tag string) (string, error) {
+	args := m.Called(tag)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) GetInstalledPathIgnoringBuild(version string) (string, error) {
+	args := m.Called(version)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) UpgradeWithinMajor() (tag, url string, upgradeAvailable bool, err error) {
+	args := m.Called()
+
+	return args.String(0), args.String(1), args.Bool(2), args.Error(3)
+}
+
+func (m *MockVersions) NextReleaseVersion(bump BumpKind) (string, error) {
+	args := m.Called(bump)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) NextVersion(current string, bump BumpKind) (string, error) {
+	args := m.Called(current, bump)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) IsBreakingUpgrade(from, to string) (bool, error) {
+	args := m.Called(from, to)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockVersions) WhichInstalled(exePath string) (tag string, err error) {
+	args := m.Called(exePath)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) VerifyIntegrity(tag string) error {
+	args := m.Called(tag)
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) ListHealthyInstalled(constraint string) (map[string]string, error) {
+	args := m.Called(constraint)
+
+	if ma, ok := args.Get(0).(map[string]string); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) VerifyAll(constraint string) (map[string]error, error) {
+	args := m.Called(constraint)
+
+	if ma, ok := args.Get(0).(map[string]error); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) GenerateEnv(tag string) (map[string]string, error) {
+	args := m.Called(tag)
+
+	if ma, ok := args.Get(0).(map[string]string); ok {
+		return ma, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) InstalledStats() (int, string, string, error) {
+	args := m.Called()
+
+	return args.Int(0), args.String(1), args.String(2), args.Error(3)
+}
+
+func (m *MockVersions) ListWithInstallState(constraint string) ([]VersionState, error) {
+	args := m.Called(constraint)
+
+	if vs, ok := args.Get(0).([]VersionState); ok {
+		return vs, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) InstalledVersionsSorted(descending bool) ([]InstalledVersion, error) {
+	args := m.Called(descending)
+
+	if iv, ok := args.Get(0).([]InstalledVersion); ok {
+		return iv, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) InstalledOlderThan(d time.Duration) ([]string, error) {
+	args := m.Called(d)
+
+	if tags, ok := args.Get(0).([]string); ok {
+		return tags, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) StaleInstalled(constraint string) ([]string, error) {
+	args := m.Called(constraint)
+
+	if tags, ok := args.Get(0).([]string); ok {
+		return tags, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 func (m *MockVersions) SortMapKeys(ma map[string]string, descending bool) []string {
 	args := m.Called(ma, descending)
 
@@ -56,6 +484,72 @@ func (m *MockVersions) SortMapKeys(ma map[string]string, descending bool) []stri
 	return nil
 }
 
+func (m *MockVersions) MigrateLayout(target LayoutKind) ([]string, error) {
+	args := m.Called(target)
+
+	if tags, ok := args.Get(0).([]string); ok {
+		return tags, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) SetCurrent(tag string) error {
+	args := m.Called(tag)
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) InstallAndActivate(constraint string) (tag, path string, err error) {
+	args := m.Called(constraint)
+
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockVersions) GetCurrent() (string, error) {
+	args := m.Called()
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockVersions) ListQuarantine() ([]QuarantinedAsset, error) {
+	args := m.Called()
+
+	if qa, ok := args.Get(0).([]QuarantinedAsset); ok {
+		return qa, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+func (m *MockVersions) ClearQuarantine() error {
+	args := m.Called()
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) VerifyReleaseCommit(tag string) (bool, error) {
+	args := m.Called(tag)
+
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockVersions) Ping() error {
+	args := m.Called()
+
+	return args.Error(0)
+}
+
+func (m *MockVersions) ListOrgRepos(org string) ([]string, error) {
+	args := m.Called(org)
+
+	if repos, ok := args.Get(0).([]string); ok {
+		return repos, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 func (m *MockVersions) InRange(version string, constraint string) (bool, error) {
 	args := m.Called(version, constraint)
 
@@ -1,16 +1,27 @@
 package gvm
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/go-github/github"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
 )
 
-func setup(t *testing.T) (string, *VersionsImpl) {
+func setup(t testing.TB) (string, *VersionsImpl) {
 	dlPath, _ := ioutil.TempDir("", "")
 
 	// clean up
@@ -49,6 +60,221 @@ func setup(t *testing.T) (string, *VersionsImpl) {
 	return dlPath, v.(*VersionsImpl)
 }
 
+// stubGitHub is a fake GitHub API which serves releases and their assets
+type stubGitHub struct {
+	*httptest.Server
+	mux      *http.ServeMux
+	releases []*github.RepositoryRelease
+	assets   map[string][]byte
+	latest   string
+	tags     []*github.RepositoryTag
+	refs     map[string]string
+	// annotated are the annotated tag objects keyed by SHA, refs which reference
+	// one of these SHAs are returned with the type tag
+	annotated map[string]string
+	// releasePages is the number of requests made to list releases
+	releasePages int
+	// commitDates are the committer dates of the commits keyed by tag
+	commitDates map[string]time.Time
+	// releaseErrors are the status codes returned for the next requests to list releases
+	releaseErrors []int
+	// retryAfter when set is returned in the Retry-After header with releaseErrors
+	retryAfter string
+	// mutex protects the request counters which are updated concurrently by the server
+	mutex sync.Mutex
+}
+
+// setupStub returns a Versions which uses a stub GitHub API rather than github.com
+func setupStub(t testing.TB) (string, *VersionsImpl, *stubGitHub) {
+	tmp, v := setup(t)
+
+	s := &stubGitHub{mux: http.NewServeMux(), assets: map[string][]byte{}, refs: map[string]string{}, annotated: map[string]string{}, commitDates: map[string]time.Time{}}
+	s.Server = httptest.NewServer(s.mux)
+	t.Cleanup(s.Close)
+
+	base := "/repos/nicholasjackson/fake-service/releases"
+	s.mux.HandleFunc(base, func(rw http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.releasePages++
+
+		if len(s.releaseErrors) > 0 {
+			if s.retryAfter != "" {
+				rw.Header().Set("Retry-After", s.retryAfter)
+			}
+
+			rw.WriteHeader(s.releaseErrors[0])
+			s.releaseErrors = s.releaseErrors[1:]
+			return
+		}
+
+		writePage(rw, r, s.releases)
+	})
+
+	s.mux.HandleFunc(base+"/tags/", func(rw http.ResponseWriter, r *http.Request) {
+		s.writeRelease(rw, r, strings.TrimPrefix(r.URL.Path, base+"/tags/"))
+	})
+
+	s.mux.HandleFunc(base+"/latest", func(rw http.ResponseWriter, r *http.Request) {
+		s.writeRelease(rw, r, s.latest)
+	})
+
+	s.mux.HandleFunc(base+"/", func(rw http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, base+"/"), 10, 64)
+		for _, rel := range s.releases {
+			if rel.GetID() == id {
+				json.NewEncoder(rw).Encode(rel)
+				return
+			}
+		}
+
+		http.NotFound(rw, r)
+	})
+
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service/tags", func(rw http.ResponseWriter, r *http.Request) {
+		writePage(rw, r, s.tags)
+	})
+
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service/git/refs/tags/", func(rw http.ResponseWriter, r *http.Request) {
+		tag := strings.TrimPrefix(r.URL.Path, "/repos/nicholasjackson/fake-service/git/refs/tags/")
+		sha, ok := s.refs[tag]
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+
+		json.NewEncoder(rw).Encode(&github.Reference{
+			Ref:    github.String("refs/tags/" + tag),
+			Object: s.gitObject(sha),
+		})
+	})
+
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service/commits/", func(rw http.ResponseWriter, r *http.Request) {
+		tag := strings.TrimPrefix(r.URL.Path, "/repos/nicholasjackson/fake-service/commits/")
+		date, ok := s.commitDates[tag]
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+
+		json.NewEncoder(rw).Encode(&github.RepositoryCommit{
+			Commit: &github.Commit{Committer: &github.CommitAuthor{Date: &date}},
+		})
+	})
+
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service/git/tags/", func(rw http.ResponseWriter, r *http.Request) {
+		sha := strings.TrimPrefix(r.URL.Path, "/repos/nicholasjackson/fake-service/git/tags/")
+		target, ok := s.annotated[sha]
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+
+		json.NewEncoder(rw).Encode(&github.Tag{
+			SHA:    github.String(sha),
+			Object: s.gitObject(target),
+		})
+	})
+
+	s.mux.HandleFunc("/download/", func(rw http.ResponseWriter, r *http.Request) {
+		d, ok := s.assets[r.URL.Path]
+		if !ok {
+			http.NotFound(rw, r)
+			return
+		}
+
+		rw.Write(d)
+	})
+
+	v.client.BaseURL, _ = url.Parse(s.URL + "/")
+	// the stub server does not use TLS
+	v.options.AllowedSchemes = []string{"http"}
+
+	return tmp, v, s
+}
+
+// gitObject returns the git object for the SHA, annotated tag objects have the type tag
+func (s *stubGitHub) gitObject(sha string) *github.GitObject {
+	if _, ok := s.annotated[sha]; ok {
+		return &github.GitObject{Type: github.String("tag"), SHA: github.String(sha)}
+	}
+
+	return &github.GitObject{Type: github.String("commit"), SHA: github.String(sha)}
+}
+
+// writePage writes the page of items requested with the page and per_page
+// parameters, setting the Link header when there is a next page
+func writePage(rw http.ResponseWriter, r *http.Request, items interface{}) {
+	all := reflect.ValueOf(items)
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage == 0 {
+		perPage = 30
+	}
+
+	start := (page - 1) * perPage
+	if start > all.Len() {
+		start = all.Len()
+	}
+
+	end := start + perPage
+	if end >= all.Len() {
+		end = all.Len()
+	} else {
+		next := *r.URL
+		q := next.Query()
+		q.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = q.Encode()
+
+		rw.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.String()))
+	}
+
+	json.NewEncoder(rw).Encode(all.Slice(start, end).Interface())
+}
+
+// writeRelease writes the release with the given tag or a 404 when not found
+func (s *stubGitHub) writeRelease(rw http.ResponseWriter, r *http.Request, tag string) {
+	for _, rel := range s.releases {
+		if *rel.TagName == tag {
+			json.NewEncoder(rw).Encode(rel)
+			return
+		}
+	}
+
+	http.NotFound(rw, r)
+}
+
+// addRelease adds a release with the given assets to the stub, the content of
+// each asset is the asset name
+func (s *stubGitHub) addRelease(tag string, assets ...string) *github.RepositoryRelease {
+	r := &github.RepositoryRelease{
+		ID:      github.Int64(int64(len(s.releases) + 1)),
+		TagName: github.String(tag),
+	}
+
+	for _, a := range assets {
+		p := fmt.Sprintf("/download/%s/%s", tag, a)
+		s.assets[p] = []byte(a)
+
+		r.Assets = append(r.Assets, github.ReleaseAsset{
+			ID:                 github.Int64(int64(len(s.assets))),
+			Name:               github.String(a),
+			BrowserDownloadURL: github.String(s.URL + p),
+			UpdatedAt:          &github.Timestamp{Time: time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)},
+		})
+	}
+
+	s.releases = append(s.releases, r)
+
+	return r
+}
+
 func TestListReleasesGetsFromGitHub(t *testing.T) {
 	_, v := setup(t)
 
@@ -144,3 +370,1219 @@ func TestListInstalledReturnsLatestVersion(t *testing.T) {
 
 	assert.Contains(t, r, "v0.14.2")
 }
+
+func TestListWithInstallStateMarksInstalledVersions(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.15.0", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+
+	os.MkdirAll(path.Join(tmp, "v0.14.2"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.2", "fake-service-linux"))
+
+	vs, err := v.ListWithInstallState("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []VersionState{
+		{Tag: "v0.15.0", URL: s.URL + "/download/v0.15.0/fake-service-linux"},
+		{Tag: "v0.14.2", URL: s.URL + "/download/v0.14.2/fake-service-linux", Installed: true},
+		{Tag: "v0.14.1", URL: s.URL + "/download/v0.14.1/fake-service-linux"},
+	}, vs)
+}
+
+func TestListWithInstallStateFiltersByConstraint(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.15.0", "fake-service-linux")
+
+	os.MkdirAll(path.Join(tmp, "v0.15.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.15.0", "fake-service-linux"))
+
+	vs, err := v.ListWithInstallState("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, vs, 1)
+	assert.Equal(t, "v0.14.1", vs[0].Tag)
+	assert.False(t, vs[0].Installed)
+}
+
+func TestInstalledVersionsSortedReturnsAscending(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.2"), os.ModePerm)
+	ioutil.WriteFile(path.Join(tmp, "v0.14.2", "fake-service-linux"), []byte("abc"), os.ModePerm)
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	ioutil.WriteFile(path.Join(tmp, "v0.14.1", "fake-service-linux"), []byte("a"), os.ModePerm)
+
+	r, err := v.InstalledVersionsSorted(false)
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 2)
+	assert.Equal(t, "v0.14.1", r[0].Tag)
+	assert.Equal(t, path.Join(tmp, "v0.14.1", "fake-service-linux"), r[0].Path)
+	assert.Equal(t, int64(1), r[0].Size)
+	assert.False(t, r[0].InstallTime.IsZero())
+	assert.Equal(t, "v0.14.2", r[1].Tag)
+	assert.Equal(t, int64(3), r[1].Size)
+}
+
+func TestInstalledVersionsSortedSkipsVersionsWithMissingExecutable(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.2"), os.ModePerm)
+	ioutil.WriteFile(path.Join(tmp, "v0.14.2", "fake-service-linux"), []byte("abc"), os.ModePerm)
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+
+	r, err := v.InstalledVersionsSorted(false)
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Equal(t, "v0.14.2", r[0].Tag)
+}
+
+func TestInstalledVersionsSortedUsesInstallTimeFromMetadata(t *testing.T) {
+	tmp, v := setup(t)
+	installedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tag := range []string{"v0.14.1", "v0.14.2"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	v.metadataStore().Set(Metadata{Tag: "v0.14.1", InstalledAt: installedAt})
+
+	old := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	os.Chtimes(path.Join(tmp, "v0.14.2", "fake-service-linux"), old, old)
+
+	r, err := v.InstalledVersionsSorted(false)
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 2)
+	assert.True(t, installedAt.Equal(r[0].InstallTime))
+	assert.True(t, old.Equal(r[1].InstallTime))
+}
+
+func TestInstalledVersionsSortedReturnsDescending(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+	os.MkdirAll(path.Join(tmp, "v0.14.2"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.2", "fake-service-linux"))
+
+	r, err := v.InstalledVersionsSorted(true)
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 2)
+	assert.Equal(t, "v0.14.2", r[0].Tag)
+	assert.Equal(t, "v0.14.1", r[1].Tag)
+}
+
+func TestListInstalledUsesConstraintFromEnvWhenEmpty(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ConstraintEnvVar = "GVM_TEST_VERSION"
+	os.Setenv("GVM_TEST_VERSION", "~v0.14.0")
+	t.Cleanup(func() {
+		os.Unsetenv("GVM_TEST_VERSION")
+	})
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+	os.MkdirAll(path.Join(tmp, "v0.15.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.15.0", "fake-service-linux"))
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+
+	assert.Contains(t, r, "v0.14.1")
+	assert.NotContains(t, r, "v0.15.0")
+}
+
+func TestConstraintFromEnvDoesNotFilterInternalListings(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ConstraintEnvVar = "GVM_TEST_VERSION"
+	os.Setenv("GVM_TEST_VERSION", "~v0.14.0")
+	t.Cleanup(func() {
+		os.Unsetenv("GVM_TEST_VERSION")
+	})
+
+	for _, tag := range []string{"v0.13.0", "v0.14.1", "v0.15.0"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	count, latest, oldest, err := v.InstalledStats()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "v0.15.0", latest)
+	assert.Equal(t, "v0.13.0", oldest)
+
+	tag, err := v.WhichInstalled(path.Join(tmp, "v0.15.0", "fake-service-linux"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.15.0", tag)
+
+	sorted, err := v.InstalledVersionsSorted(false)
+	assert.NoError(t, err)
+	assert.Len(t, sorted, 3)
+
+	// the public resolution APIs still read the constraint from the environment
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Len(t, r, 1)
+	assert.Contains(t, r, "v0.14.1")
+}
+
+func TestListInstalledPrefersExplicitConstraintOverEnv(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ConstraintEnvVar = "GVM_TEST_VERSION"
+	os.Setenv("GVM_TEST_VERSION", "~v0.14.0")
+	t.Cleanup(func() {
+		os.Unsetenv("GVM_TEST_VERSION")
+	})
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+	os.MkdirAll(path.Join(tmp, "v0.15.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.15.0", "fake-service-linux"))
+
+	r, err := v.ListInstalledVersions("~v0.15.0")
+	assert.NoError(t, err)
+
+	assert.NotContains(t, r, "v0.14.1")
+	assert.Contains(t, r, "v0.15.0")
+}
+
+func TestGetAssetModTimeReturnsUpdatedTime(t *testing.T) {
+	_, v, s := setupStub(t)
+	r := s.addRelease("v0.14.1", "fake-service-osx", "fake-service-linux")
+	r.Assets[1].UpdatedAt = &github.Timestamp{Time: time.Date(2020, 9, 2, 10, 0, 0, 0, time.UTC)}
+
+	mt, err := v.GetAssetModTime("v0.14.1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Date(2020, 9, 2, 10, 0, 0, 0, time.UTC), mt.UTC())
+}
+
+func TestGetAssetModTimeReturnsErrorWhenNoAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-osx")
+
+	_, err := v.GetAssetModTime("v0.14.1")
+	assert.Error(t, err)
+}
+
+func TestGetAssetModTimeReturnsErrorWhenNoRelease(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.GetAssetModTime("v0.14.1")
+	assert.Error(t, err)
+}
+
+func TestListReleasesMatchesOSSynonym(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "tool-macos", "tool-linux")
+	v.options.GOOS = "darwin"
+	v.options.UseOSSynonyms = true
+	v.options.AssetNameFunc = func(ver, goos, goarch string) string {
+		return fmt.Sprintf("tool-%s", goos)
+	}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.1/tool-macos", r["v0.14.1"])
+}
+
+func TestListReleasesDoesNotMatchOSSynonymWhenDisabled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "tool-macos", "tool-linux")
+	v.options.GOOS = "darwin"
+	v.options.AssetNameFunc = func(ver, goos, goarch string) string {
+		return fmt.Sprintf("tool-%s", goos)
+	}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.NotContains(t, r, "v0.14.1")
+}
+
+func TestListReleasesAppliesPlatformMapFunc(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "tool-macos-x86_64", "tool-darwin-amd64")
+	v.options.GOOS = "darwin"
+	v.options.GOARCH = "amd64"
+	v.options.PlatformMapFunc = func(goos, goarch string) (string, string) {
+		return map[string]string{"darwin": "macos"}[goos], map[string]string{"amd64": "x86_64"}[goarch]
+	}
+	v.options.AssetNameFunc = func(ver, goos, goarch string) string {
+		return fmt.Sprintf("tool-%s-%s", goos, goarch)
+	}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.1/tool-macos-x86_64", r["v0.14.1"])
+}
+
+func TestFrozenListReleasesReturnsInstalledVersions(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	v.options.Frozen = true
+
+	fn := path.Join(tmp, "v0.14.1", "fake-service-linux")
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(fn)
+
+	tag, url, err := v.GetLatestReleaseURL("~v0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, fn, url)
+}
+
+func TestFrozenListReleasesReturnsErrFrozenWhenNotInstalled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	v.options.Frozen = true
+
+	_, err := v.ListReleases("~v0.14.0")
+	assert.Equal(t, ErrFrozen, err)
+}
+
+func TestFrozenDownloadReturnsInstalledPath(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.Frozen = true
+
+	fn := path.Join(tmp, "v0.14.1", "fake-service-linux")
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(fn)
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.Equal(t, fn, fp)
+}
+
+func TestFrozenDownloadReturnsErrFrozenWhenNotInstalled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.Frozen = true
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.Equal(t, ErrFrozen, err)
+}
+
+func TestWhichInstalledReturnsTag(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+	os.MkdirAll(path.Join(tmp, "v0.14.2"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.2", "fake-service-linux"))
+
+	tag, err := v.WhichInstalled(path.Join(tmp, "v0.14.2", "fake-service-linux"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.2", tag)
+}
+
+func TestWhichInstalledReturnsCurrentForActiveExecutable(t *testing.T) {
+	for _, mode := range []ActivationMode{ActivationSymlink, ActivationCopy} {
+		tmp, v := setup(t)
+		v.options.ActivationMode = mode
+		installExe(t, tmp, "v0.14.1", "v0.14.1")
+		installExe(t, tmp, "v0.14.2", "v0.14.2")
+
+		err := v.SetCurrent("v0.14.1")
+		assert.NoError(t, err)
+
+		tag, err := v.WhichInstalled(path.Join(tmp, "bin", "fake-service"))
+		assert.NoError(t, err, mode)
+		assert.Equal(t, "v0.14.1", tag, mode)
+
+		// a link on the PATH to the active executable
+		link := path.Join(tmp, "fake-service-link")
+		os.Remove(link)
+		assert.NoError(t, os.Symlink(path.Join(tmp, "bin", "fake-service"), link))
+
+		tag, err = v.WhichInstalled(link)
+		assert.NoError(t, err, mode)
+		assert.Equal(t, "v0.14.1", tag, mode)
+	}
+}
+
+func TestWhichInstalledReturnsErrNotManagedOutsideReleasesPath(t *testing.T) {
+	_, v := setup(t)
+
+	_, err := v.WhichInstalled("/usr/local/bin/fake-service-linux")
+	assert.Equal(t, ErrNotManaged, err)
+}
+
+func TestWhichInstalledReturnsErrNotManagedForUnknownFile(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "other"))
+
+	_, err := v.WhichInstalled(path.Join(tmp, "v0.14.1", "other"))
+	assert.Equal(t, ErrNotManaged, err)
+}
+
+func TestGetLatestExcludingSkipsExcludedTag(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	tag, url, err := v.GetLatestExcluding("", "v0.14.2")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", url)
+
+	tag, _, err = v.GetLatestExcluding("", "0.14.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+
+	tag, _, err = v.GetLatestExcluding("", "v0.14.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.2", tag)
+}
+
+func TestGetLatestExcludingReturnsEmptyWhenOnlyExcludedMatches(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	tag, url, err := v.GetLatestExcluding("~0.14.0", "v0.14.2")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", tag)
+	assert.Equal(t, "", url)
+}
+
+func TestGetEffectiveLatestReturnsGitHubLatest(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.latest = "v0.14.1"
+
+	tag, url, err := v.GetEffectiveLatest("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", url)
+}
+
+func TestGetEffectiveLatestFallsBackWhenLatestOutsideConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.15.0", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.latest = "v0.15.0"
+
+	tag, _, err := v.GetEffectiveLatest("~v0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.2", tag)
+}
+
+func TestGetEffectiveLatestFallsBackWhenLatestNotSemanticVersion(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("nightly", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.latest = "nightly"
+
+	tag, _, err := v.GetEffectiveLatest("~v0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.2", tag)
+}
+
+func TestGetEffectiveLatestReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.latest = "v0.14.1"
+
+	_, _, err := v.GetEffectiveLatest("not a constraint")
+	assert.Error(t, err)
+}
+
+func TestGetEffectiveLatestFallsBackWhenNoLatest(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	tag, _, err := v.GetEffectiveLatest("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.2", tag)
+}
+
+func TestUpgradeWithinMajorDoesNotCrossMajor(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v2.0.0", "fake-service-linux")
+	s.addRelease("v1.3.0", "fake-service-linux")
+	s.addRelease("v1.2.0", "fake-service-linux")
+
+	os.MkdirAll(path.Join(tmp, "v1.2.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v1.2.0", "fake-service-linux"))
+
+	tag, url, ok, err := v.UpgradeWithinMajor()
+	assert.NoError(t, err)
+
+	assert.True(t, ok)
+	assert.Equal(t, "v1.3.0", tag)
+	assert.Equal(t, s.URL+"/download/v1.3.0/fake-service-linux", url)
+}
+
+func TestUpgradeWithinMajorReturnsFalseWhenLatestInMajor(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v2.0.0", "fake-service-linux")
+	s.addRelease("v1.3.0", "fake-service-linux")
+
+	os.MkdirAll(path.Join(tmp, "v1.3.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v1.3.0", "fake-service-linux"))
+
+	tag, _, ok, err := v.UpgradeWithinMajor()
+	assert.NoError(t, err)
+
+	assert.False(t, ok)
+	assert.Equal(t, "v1.3.0", tag)
+}
+
+func TestUpgradeWithinMajorReturnsErrorWhenNotInstalled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.3.0", "fake-service-linux")
+
+	_, _, _, err := v.UpgradeWithinMajor()
+	assert.Equal(t, ErrNotInstalled, err)
+}
+
+func TestNextReleaseVersionBumpsLatestRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.4.2", "fake-service-linux")
+	s.addRelease("v1.3.0", "fake-service-linux")
+
+	tt := map[BumpKind]string{
+		BumpMajor: "v2.0.0",
+		BumpMinor: "v1.5.0",
+		BumpPatch: "v1.4.3",
+	}
+
+	for bump, expected := range tt {
+		next, err := v.NextReleaseVersion(bump)
+		assert.NoError(t, err)
+
+		assert.Equal(t, expected, next)
+	}
+}
+
+func TestNextReleaseVersionReturnsErrorForInvalidBump(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.4.2", "fake-service-linux")
+
+	_, err := v.NextReleaseVersion("mega")
+	assert.Error(t, err)
+}
+
+func TestNextVersionBumpsCurrent(t *testing.T) {
+	_, v := setup(t)
+
+	tt := []struct {
+		current  string
+		bump     BumpKind
+		expected string
+	}{
+		{"v1.4.2", BumpMajor, "v2.0.0"},
+		{"v1.4.2", BumpMinor, "v1.5.0"},
+		{"v1.4.2", BumpPatch, "v1.4.3"},
+		{"1.4.2", BumpPatch, "1.4.3"},
+		{"v1.4.3-beta.1", BumpMajor, "v2.0.0"},
+		{"v1.4.3-beta.1", BumpMinor, "v1.5.0"},
+		{"v1.4.3-beta.1", BumpPatch, "v1.4.3"},
+		{"v1.4.2+build.5", BumpPatch, "v1.4.3"},
+	}
+
+	for _, tc := range tt {
+		next, err := v.NextVersion(tc.current, tc.bump)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tc.expected, next, "%s %s", tc.current, tc.bump)
+	}
+}
+
+func TestNextVersionReturnsErrorForInvalidVersion(t *testing.T) {
+	_, v := setup(t)
+
+	_, err := v.NextVersion("abc", BumpPatch)
+	assert.Error(t, err)
+
+	_, err = v.NextVersion("v1.0.0", "mega")
+	assert.Error(t, err)
+}
+
+func TestListReleasesSelectsRawBinaryByDefault(t *testing.T) {
+	for _, assets := range [][]string{
+		{"fake-service-linux", "fake-service-linux.tar.gz"},
+		{"fake-service-linux.tar.gz", "fake-service-linux"},
+	} {
+		_, v, s := setupStub(t)
+		s.addRelease("v0.14.1", assets...)
+
+		r, err := v.ListReleases("")
+		assert.NoError(t, err)
+
+		assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", r["v0.14.1"])
+	}
+}
+
+func TestListReleasesSelectsArchiveWhenPreferArchive(t *testing.T) {
+	for _, assets := range [][]string{
+		{"fake-service-linux", "fake-service-linux.zip", "fake-service-linux.tar.gz"},
+		{"fake-service-linux.zip", "fake-service-linux.tar.gz", "fake-service-linux"},
+	} {
+		_, v, s := setupStub(t)
+		v.options.PreferArchive = true
+		s.addRelease("v0.14.1", assets...)
+
+		r, err := v.ListReleases("")
+		assert.NoError(t, err)
+
+		assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux.tar.gz", r["v0.14.1"])
+	}
+}
+
+func TestListReleasesMatchesArchiveOfAssetName(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux.zip")
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux.zip", r["v0.14.1"])
+}
+
+func TestListReleasesUsesTags(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.UseTags = true
+
+	for i := 0; i < 150; i++ {
+		s.tags = append(s.tags, &github.RepositoryTag{Name: github.String(fmt.Sprintf("v0.%d.0", i))})
+	}
+	s.tags = append(s.tags, &github.RepositoryTag{Name: github.String("nightly")})
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 150)
+	assert.NotContains(t, r, "nightly")
+	assert.Equal(t, "https://github.com/nicholasjackson/fake-service/archive/v0.149.0.tar.gz", r["v0.149.0"])
+
+	r, err = v.ListReleases(">= v0.140.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 10)
+}
+
+func addTag(s *stubGitHub, name, sha string) {
+	s.tags = append(s.tags, &github.RepositoryTag{Name: github.String(name), Commit: &github.Commit{SHA: github.String(sha)}})
+}
+
+func TestGetTaggedReleaseResolvesNamedTag(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.15.0", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	addTag(s, "canary", "ccc")
+	addTag(s, "stable", "bbb")
+	addTag(s, "v0.15.0", "ccc")
+	addTag(s, "v0.14.1", "bbb")
+	addTag(s, "v0.14.0", "aaa")
+
+	tag, url, err := v.GetTaggedRelease("stable")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", url)
+
+	tag, _, err = v.GetTaggedRelease("canary")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.15.0", tag)
+}
+
+func TestGetTaggedReleaseSkipsReleasesWithoutAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1+build.2", "fake-service-darwin")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	addTag(s, "stable", "bbb")
+	addTag(s, "v0.14.1+build.2", "bbb")
+	addTag(s, "v0.14.1", "bbb")
+
+	tag, _, err := v.GetTaggedRelease("stable")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+}
+
+func TestGetTaggedReleaseReturnsErrorWhenTagUnknown(t *testing.T) {
+	_, v, s := setupStub(t)
+	addTag(s, "v0.14.1", "bbb")
+
+	_, _, err := v.GetTaggedRelease("stable")
+	assert.True(t, xerrors.Is(err, ErrUnknownTag))
+}
+
+func TestGetTaggedReleaseReturnsErrorWhenNoRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	addTag(s, "stable", "bbb")
+	addTag(s, "v0.14.1", "bbb")
+
+	_, _, err := v.GetTaggedRelease("stable")
+	assert.Error(t, err)
+}
+
+func TestListReleasesWithAssetFuncUsesGivenFunc(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.CacheTTL = time.Minute
+	s.addRelease("v0.14.1", "fake-service-linux", "other-tool-linux")
+	s.addRelease("v0.14.0", "fake-service-linux")
+
+	r, err := v.ListReleasesWithAssetFunc("", func(ver, goos, goarch string) string {
+		return "other-tool-" + goos
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Equal(t, s.URL+"/download/v0.14.1/other-tool-linux", r["v0.14.1"])
+
+	// the options and cache are not changed by the call
+	r, err = v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 2)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", r["v0.14.1"])
+}
+
+func TestListReleasesFallsBackToTagsWhenNoReleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.FallbackToTags = true
+	s.tags = append(s.tags,
+		&github.RepositoryTag{Name: github.String("v0.14.1")},
+		&github.RepositoryTag{Name: github.String("v0.13.0")},
+	)
+
+	r, err := v.ListReleases("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Equal(t, "https://github.com/nicholasjackson/fake-service/archive/v0.14.1.tar.gz", r["v0.14.1"])
+}
+
+func TestListReleasesDoesNotFallBackToTagsWhenReleasesExist(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.FallbackToTags = true
+	s.addRelease("v0.14.0", "fake-service-linux")
+	s.tags = append(s.tags, &github.RepositoryTag{Name: github.String("v0.14.1")})
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Contains(t, r, "v0.14.0")
+}
+
+func TestListReleasesDoesNotFallBackToTagsWhenDisabled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.tags = append(s.tags, &github.RepositoryTag{Name: github.String("v0.14.1")})
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 0)
+}
+
+func TestCompleteVersionsFiltersByPrefix(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.15.0")
+	s.addRelease("v0.14.10", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.1.0", "fake-service-linux")
+	s.addRelease("nightly", "fake-service-linux")
+
+	tags, err := v.CompleteVersions("0.14")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.14.10", "v0.14.2"}, tags)
+
+	tags, err = v.CompleteVersions("v0.1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.15.0", "v0.14.10", "v0.14.2", "v0.1.0"}, tags)
+}
+
+func TestCompleteVersionsReturnsAllWithEmptyPrefix(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2")
+	s.addRelease("v0.14.1")
+
+	tags, err := v.CompleteVersions("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.14.2", "v0.14.1"}, tags)
+}
+
+func TestListInstalledReturnsEmptyWhenReleasesPathDoesNotExist(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.ReleasesPath = path.Join(tmp, "does-not-exist")
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 0)
+}
+
+func TestListOrgReposReturnsAllPages(t *testing.T) {
+	_, v, s := setupStub(t)
+
+	repos := []*github.Repository{}
+	for i := 0; i < 120; i++ {
+		repos = append(repos, &github.Repository{Name: github.String(fmt.Sprintf("repo-%d", i))})
+	}
+
+	s.mux.HandleFunc("/orgs/shipyard-run/repos", func(rw http.ResponseWriter, r *http.Request) {
+		writePage(rw, r, repos)
+	})
+
+	r, err := v.ListOrgRepos("shipyard-run")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 120)
+	assert.Equal(t, "repo-0", r[0])
+	assert.Equal(t, "repo-119", r[119])
+}
+
+func TestListOrgReposReturnsErrorForUnknownOrg(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.ListOrgRepos("unknown")
+	assert.Error(t, err)
+}
+
+func TestListOrgReposReturnsErrorWhenFrozen(t *testing.T) {
+	_, v, _ := setupStub(t)
+	v.options.Frozen = true
+
+	_, err := v.ListOrgRepos("shipyard-run")
+	assert.True(t, xerrors.Is(err, ErrFrozen))
+}
+
+func TestGetLatestReleaseURLBreaksTiesOnBuildMetadata(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.2.3+build1", "fake-service-linux")
+	s.addRelease("v1.2.3+build2", "fake-service-linux")
+	s.addRelease("v1.2.2", "fake-service-linux")
+
+	for i := 0; i < 20; i++ {
+		tag, _, err := v.GetLatestReleaseURL("")
+		assert.NoError(t, err)
+
+		assert.Equal(t, "v1.2.3+build2", tag)
+	}
+}
+
+func TestSortMapKeysOrdersBuildMetadataByTag(t *testing.T) {
+	_, v := setup(t)
+
+	m := map[string]string{"v1.2.3+build2": "", "v1.2.3+build1": "", "v1.2.4": "", "v1.2.3+build10": ""}
+
+	assert.Equal(t, []string{"v1.2.3+build1", "v1.2.3+build10", "v1.2.3+build2", "v1.2.4"}, v.SortMapKeys(m, false))
+	assert.Equal(t, []string{"v1.2.4", "v1.2.3+build2", "v1.2.3+build10", "v1.2.3+build1"}, v.SortMapKeys(m, true))
+}
+
+func TestSortMapKeysDropsKeysWhichAreNotSemanticVersions(t *testing.T) {
+	_, v := setup(t)
+
+	m := map[string]string{"v1.2.3": "", "nightly": "", "v1.2.4": ""}
+
+	assert.Equal(t, []string{"v1.2.4", "v1.2.3"}, v.SortMapKeys(m, true))
+}
+
+func TestIsBreakingUpgrade(t *testing.T) {
+	_, v := setup(t)
+
+	tests := []struct {
+		from     string
+		to       string
+		breaking bool
+	}{
+		{"v1.4.2", "v2.0.0", true},
+		{"v1.4.2", "v1.5.0", false},
+		{"v1.4.2", "v1.4.3", false},
+		{"v2.0.0", "v1.4.2", false},
+		{"v0.12.1", "v0.13.0", true},
+		{"v0.12.1", "v0.12.2", false},
+		{"v0.12.1", "v1.0.0", true},
+	}
+
+	for _, tc := range tests {
+		b, err := v.IsBreakingUpgrade(tc.from, tc.to)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.breaking, b, "%s -> %s", tc.from, tc.to)
+	}
+}
+
+func TestIsBreakingUpgradeReturnsErrorForInvalidVersion(t *testing.T) {
+	_, v := setup(t)
+
+	_, err := v.IsBreakingUpgrade("abc", "v1.0.0")
+	assert.Error(t, err)
+}
+
+func TestLatestPerMinorReturnsLatestPatchForEachMinor(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.4.0", "fake-service-linux")
+	s.addRelease("v1.4.2", "fake-service-linux")
+	s.addRelease("v1.3.7", "fake-service-linux")
+	s.addRelease("v2.1.0", "fake-service-linux")
+
+	l, err := v.LatestPerMinor("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"1.3": "v1.3.7", "1.4": "v1.4.2", "2.1": "v2.1.0"}, l)
+}
+
+func TestLatestPerMinorFiltersByConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.4.2", "fake-service-linux")
+	s.addRelease("v2.1.0", "fake-service-linux")
+
+	l, err := v.LatestPerMinor("< 2.0.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"1.4": "v1.4.2"}, l)
+}
+
+func TestStaleInstalledReturnsVersionsOlderThanLatest(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	for _, tag := range []string{"v0.13.0", "v0.14.1", "v0.14.2"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	stale, err := v.StaleInstalled("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v0.13.0", "v0.14.1"}, stale)
+
+	stale, err = v.StaleInstalled("~0.13.0")
+	assert.NoError(t, err)
+	assert.Empty(t, stale)
+}
+
+func TestInstalledOlderThanReturnsOldVersionsExceptCurrent(t *testing.T) {
+	tmp, v := setup(t)
+	old := time.Now().Add(-400 * 24 * time.Hour)
+
+	for _, tag := range []string{"v0.12.0", "v0.13.0", "v0.14.1"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	// install time from the metadata
+	v.metadataStore().Set(Metadata{Tag: "v0.12.0", InstalledAt: old})
+	// install time from the executable
+	os.Chtimes(path.Join(tmp, "v0.13.0", "fake-service-linux"), old, old)
+	os.Chtimes(path.Join(tmp, "v0.14.1", "fake-service-linux"), old, old)
+
+	err := v.SetCurrent("v0.14.1")
+	assert.NoError(t, err)
+
+	tags, err := v.InstalledOlderThan(365 * 24 * time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.12.0", "v0.13.0"}, tags)
+}
+
+func TestInstalledOlderThanIgnoresRecentInstalls(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+
+	tags, err := v.InstalledOlderThan(time.Hour)
+	assert.NoError(t, err)
+
+	assert.Empty(t, tags)
+}
+
+func TestNormalizeTagRemovesPrefix(t *testing.T) {
+	assert.Equal(t, "1.2.3", NormalizeTag("v1.2.3"))
+	assert.Equal(t, "1.2.3", NormalizeTag("1.2.3"))
+	assert.Equal(t, "1.2.3-dev", NormalizeTag("v1.2.3-dev"))
+}
+
+func TestDenormalizeTagAddsPrefix(t *testing.T) {
+	assert.Equal(t, "v1.2.3", DenormalizeTag("1.2.3"))
+	assert.Equal(t, "v1.2.3", DenormalizeTag("v1.2.3"))
+	assert.Equal(t, "v1.2.3-dev", DenormalizeTag(NormalizeTag("v1.2.3-dev")))
+}
+
+func TestNormalizeTagUsesReleasePrefix(t *testing.T) {
+	_, v := setup(t)
+
+	assert.Equal(t, "1.2.3", v.NormalizeTag("v1.2.3"))
+	assert.Equal(t, "v1.2.3", v.DenormalizeTag("1.2.3"))
+	assert.Equal(t, "v1.2.3", v.DenormalizeTag("v1.2.3"))
+}
+
+func TestNormalizeTagUsesTagPrefix(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.TagPrefix = "release-"
+
+	assert.Equal(t, "1.2.3", v.NormalizeTag("release-1.2.3"))
+	assert.Equal(t, "release-1.2.3", v.DenormalizeTag("1.2.3"))
+	assert.Equal(t, "release-1.2.3", v.DenormalizeTag(v.NormalizeTag("release-1.2.3")))
+	assert.Equal(t, "release-1.2.3-dev", v.DenormalizeTag(v.NormalizeTag("release-1.2.3-dev")))
+
+	s.addRelease("release-1.2.4", "fake-service-linux")
+	s.addRelease("release-1.2.3", "fake-service-linux")
+
+	tag, _, err := v.GetLatestReleaseURL("~1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "release-1.2.4", tag)
+
+	next, err := v.NextVersion(tag, BumpPatch)
+	assert.NoError(t, err)
+	assert.Equal(t, "release-1.2.5", next)
+}
+
+func TestResolveAllReturnsLatestReleaseMatchingEveryConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v2.0.0", "fake-service-linux")
+	s.addRelease("v1.9.3", "fake-service-linux")
+	s.addRelease("v1.8.5", "fake-service-linux")
+	s.addRelease("v1.7.0", "fake-service-linux")
+
+	tag, url, err := v.ResolveAll([]string{">= 1.8.0", "< 2.0.0", "!= 1.9.3"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v1.8.5", tag)
+	assert.Equal(t, s.URL+"/download/v1.8.5/fake-service-linux", url)
+}
+
+func TestResolveAllIgnoresReleasesWhichAreNotSemanticVersions(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("nightly", "fake-service-linux")
+	s.addRelease("v1.9.3", "fake-service-linux")
+	s.addRelease("v1.8.5", "fake-service-linux")
+
+	tag, _, err := v.ResolveAll([]string{">= 1.8.0", "< 2.0.0"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v1.9.3", tag)
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+	assert.NotContains(t, r, "nightly")
+}
+
+func TestResolveAllReturnsErrorWhenIntersectionEmpty(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v2.0.0", "fake-service-linux")
+	s.addRelease("v1.9.3", "fake-service-linux")
+
+	_, _, err := v.ResolveAll([]string{"~1.9.0", ">= 2.0.0"})
+	assert.True(t, xerrors.Is(err, ErrNoMatchingRelease))
+}
+
+func TestResolveAllMatchesPrereleasesLikeListReleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.10.0-beta.1", "fake-service-linux")
+	s.addRelease("v1.9.3", "fake-service-linux")
+
+	tag, _, err := v.ResolveAll([]string{">= 1.9.0", "< 2.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.9.3", tag)
+
+	v.options.IncludePrerelease = true
+
+	tag, _, err = v.ResolveAll([]string{">= 1.9.0", "< 2.0.0"})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10.0-beta.1", tag)
+
+	r, err := v.ListReleases(">= 1.9.0, < 2.0.0")
+	assert.NoError(t, err)
+	assert.Contains(t, r, "v1.10.0-beta.1")
+}
+
+func TestResolveAllReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, _, err := v.ResolveAll([]string{"~1.9.0", "abc"})
+	assert.Error(t, err)
+}
+
+func TestGetInstalledVersionMatchesBuildMetadataFolders(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v1.2.3+build.5"), os.ModePerm)
+	os.Create(path.Join(tmp, "v1.2.3+build.5", "fake-service-linux"))
+
+	tag, fp, err := v.GetInstalledVersion("1.2.3")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v1.2.3+build.5", tag)
+	assert.Equal(t, path.Join(tmp, "v1.2.3+build.5", "fake-service-linux"), fp)
+}
+
+func TestInstalledStatsWithNoVersions(t *testing.T) {
+	_, v := setup(t)
+
+	count, latest, oldest, err := v.InstalledStats()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, count)
+	assert.Equal(t, "", latest)
+	assert.Equal(t, "", oldest)
+}
+
+func TestInstalledStatsWithOneVersion(t *testing.T) {
+	tmp, v := setup(t)
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+
+	count, latest, oldest, err := v.InstalledStats()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "v0.14.1", latest)
+	assert.Equal(t, "v0.14.1", oldest)
+}
+
+func TestInstalledStatsWithManyVersions(t *testing.T) {
+	tmp, v := setup(t)
+	for _, tag := range []string{"v0.14.1", "v0.9.0", "v0.14.10", "v0.13.2"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+	}
+
+	count, latest, oldest, err := v.InstalledStats()
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, count)
+	assert.Equal(t, "v0.14.10", latest)
+	assert.Equal(t, "v0.9.0", oldest)
+}
+
+func TestInstalledSatisfiesReturnsHighestMatchingVersion(t *testing.T) {
+	tmp, v := setup(t)
+
+	for _, tag := range []string{"v0.13.0", "v0.14.1", "v0.14.2"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+	}
+
+	ok, tag, err := v.InstalledSatisfies("~0.14.0")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "v0.14.2", tag)
+
+	ok, tag, err = v.InstalledSatisfies(">= 1.0.0")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, "", tag)
+}
+
+func TestInstalledSatisfiesReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v := setup(t)
+
+	_, _, err := v.InstalledSatisfies("abc")
+	assert.Error(t, err)
+}
+
+func TestInstalledPathReturnsPathForExactTag(t *testing.T) {
+	tmp, v := setup(t)
+
+	for _, tag := range []string{"v1.2.3", "v1.2.4"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	for _, tag := range []string{"v1.2.3", "1.2.3"} {
+		fp, err := v.InstalledPath(tag)
+		assert.NoError(t, err)
+		assert.Equal(t, path.Join(tmp, "v1.2.3", "fake-service-linux"), fp, tag)
+	}
+}
+
+func TestInstalledPathReturnsErrorWhenNotInstalled(t *testing.T) {
+	tmp, v := setup(t)
+
+	// a folder without the executable is not installed
+	os.MkdirAll(path.Join(tmp, "v1.2.3"), os.ModePerm)
+
+	for _, tag := range []string{"v1.2.3", "v1.2.5"} {
+		_, err := v.InstalledPath(tag)
+		assert.True(t, xerrors.Is(err, ErrNotInstalled), tag)
+	}
+}
+
+func TestGetInstalledPathIgnoringBuild(t *testing.T) {
+	tmp, v := setup(t)
+
+	for _, tag := range []string{"v1.2.3+build.5", "v1.2.3+build.6", "v1.2.4"} {
+		os.MkdirAll(path.Join(tmp, tag), os.ModePerm)
+		os.Create(path.Join(tmp, tag, "fake-service-linux"))
+	}
+
+	for _, version := range []string{"1.2.3", "v1.2.3", "1.2.3+build.1"} {
+		fp, err := v.GetInstalledPathIgnoringBuild(version)
+		assert.NoError(t, err)
+		assert.Equal(t, path.Join(tmp, "v1.2.3+build.6", "fake-service-linux"), fp, version)
+	}
+
+	fp, err := v.GetInstalledPathIgnoringBuild("1.2.4")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(tmp, "v1.2.4", "fake-service-linux"), fp)
+
+	_, err = v.GetInstalledPathIgnoringBuild("1.2.5")
+	assert.Equal(t, ErrNotInstalled, err)
+
+	// pre-releases are a different version
+	_, err = v.GetInstalledPathIgnoringBuild("1.2.4-beta.1")
+	assert.Equal(t, ErrNotInstalled, err)
+}
+
+func TestGetLatestReleaseURLReturnsErrorWhenOnlyPrereleasesMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.15.0-beta.2", "fake-service-linux")
+	s.addRelease("v0.15.0-beta.1", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, _, err := v.GetLatestReleaseURL("~0.15.0")
+	assert.True(t, xerrors.Is(err, ErrOnlyPrereleasesMatch))
+
+	// no releases at all is not an error
+	tag, _, err := v.GetLatestReleaseURL("~1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "", tag)
+}
+
+func TestGetLatestReleaseURLIncludesPrereleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.IncludePrerelease = true
+	s.addRelease("v0.15.0-beta.2", "fake-service-linux")
+	s.addRelease("v0.15.0-beta.1", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	tag, _, err := v.GetLatestReleaseURL("~0.15.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.15.0-beta.2", tag)
+
+	tag, _, err = v.GetLatestReleaseURL("~0.14.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", tag)
+}
@@ -0,0 +1,93 @@
+package gvm
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// VerifyIntegrity checks the installed executable for the tag against the checksum
+// recorded in its metadata when it was installed, detecting files which have been
+// corrupted or modified on disk. Returns ErrNotInstalled when the version is not
+// installed, ErrMetadataNotFound when no metadata was recorded and
+// ErrChecksumMismatch when the executable has changed
+func (v *VersionsImpl) VerifyIntegrity(tag string) error {
+	fp := v.exePath(tag)
+	if _, err := os.Stat(fp); err != nil {
+		return ErrNotInstalled
+	}
+
+	m, err := v.metadataStore().Get(tag)
+	if err != nil {
+		return err
+	}
+
+	if m.SHA256 == "" {
+		return xerrors.Errorf("No checksum was recorded when %s was installed", tag)
+	}
+
+	sum, err := sha256File(fp)
+	if err != nil {
+		return xerrors.Errorf("Unable to read installed version %s: %w", tag, err)
+	}
+
+	if !strings.EqualFold(sum, m.SHA256) {
+		return xerrors.Errorf("%w: installed version %s expected %s, got %s", ErrChecksumMismatch, tag, m.SHA256, sum)
+	}
+
+	return nil
+}
+
+// ListHealthyInstalled returns the installed versions matching the constraint which pass
+// VerifyIntegrity, versions which have been modified or have no recorded metadata are excluded
+func (v *VersionsImpl) ListHealthyInstalled(constraint string) (map[string]string, error) {
+	installed, err := v.ListInstalledVersions(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := v.VerifyAll(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	healthy := map[string]string{}
+	for tag, fp := range installed {
+		if err, ok := results[tag]; ok && err == nil {
+			healthy[tag] = fp
+		}
+	}
+
+	return healthy, nil
+}
+
+// VerifyAll runs VerifyIntegrity for every installed version matching the constraint and
+// returns the result keyed by tag, the error is nil for versions which pass. Versions are
+// verified in parallel bounded by Concurrency, which also bounds the number of executables
+// open at once
+func (v *VersionsImpl) VerifyAll(constraint string) (map[string]error, error) {
+	installed, err := v.ListInstalledVersions(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	for tag := range installed {
+		tags = append(tags, tag)
+	}
+
+	sort.Strings(tags)
+
+	errs := parallel(len(tags), v.options.Concurrency, func(i int) error {
+		return v.VerifyIntegrity(tags[i])
+	})
+
+	results := map[string]error{}
+	for i, tag := range tags {
+		results[tag] = errs[i]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,157 @@
+package gvm
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestVerifyIntegrityPassesForUnmodifiedInstall(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	err = v.VerifyIntegrity("v0.14.1")
+	assert.NoError(t, err)
+}
+
+func TestVerifyIntegrityReturnsErrorWhenModified(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	ioutil.WriteFile(fp, []byte("tampered"), 0755)
+
+	err = v.VerifyIntegrity("v0.14.1")
+	assert.True(t, xerrors.Is(err, ErrChecksumMismatch))
+}
+
+func TestVerifyIntegrityReturnsErrorWhenNoMetadata(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+
+	err := v.VerifyIntegrity("v0.14.1")
+	assert.Equal(t, ErrMetadataNotFound, err)
+}
+
+func TestVerifyIntegrityReturnsErrorWhenNotInstalled(t *testing.T) {
+	_, v := setup(t)
+
+	err := v.VerifyIntegrity("v0.14.1")
+	assert.Equal(t, ErrNotInstalled, err)
+}
+
+func TestListHealthyInstalledExcludesCorruptedVersions(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	paths := map[string]string{}
+	for _, tag := range []string{"v0.13.0", "v0.14.1", "v0.14.2"} {
+		fp, err := v.DownloadRelease(tag, s.URL+"/download/"+tag+"/fake-service-linux")
+		assert.NoError(t, err)
+
+		paths[tag] = fp
+	}
+
+	ioutil.WriteFile(paths["v0.14.1"], []byte("corrupted"), 0755)
+
+	// installed without metadata
+	os.MkdirAll(path.Join(tmp, "v0.12.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.12.0", "fake-service-linux"))
+
+	r, err := v.ListHealthyInstalled("")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"v0.13.0": paths["v0.13.0"], "v0.14.2": paths["v0.14.2"]}, r)
+
+	r, err = v.ListHealthyInstalled("~0.14.0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"v0.14.2": paths["v0.14.2"]}, r)
+}
+
+func TestVerifyAllReturnsResultForEachInstalledVersion(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.Concurrency = 3
+
+	tags := []string{"v0.10.0", "v0.11.0", "v0.12.0", "v0.13.0", "v0.14.0", "v0.14.1", "v0.14.2"}
+	paths := map[string]string{}
+
+	for _, tag := range tags {
+		s.addRelease(tag, "fake-service-linux")
+
+		fp, err := v.DownloadRelease(tag, s.URL+"/download/"+tag+"/fake-service-linux")
+		assert.NoError(t, err)
+
+		paths[tag] = fp
+	}
+
+	ioutil.WriteFile(paths["v0.11.0"], []byte("corrupted"), 0755)
+	ioutil.WriteFile(paths["v0.14.1"], []byte("corrupted"), 0755)
+
+	// installed without metadata
+	os.MkdirAll(path.Join(tmp, "v0.9.0"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.9.0", "fake-service-linux"))
+
+	r, err := v.VerifyAll("")
+	assert.NoError(t, err)
+	assert.Len(t, r, 8)
+
+	for _, tag := range []string{"v0.10.0", "v0.12.0", "v0.13.0", "v0.14.0", "v0.14.2"} {
+		assert.NoError(t, r[tag], tag)
+	}
+
+	assert.True(t, xerrors.Is(r["v0.11.0"], ErrChecksumMismatch))
+	assert.True(t, xerrors.Is(r["v0.14.1"], ErrChecksumMismatch))
+	assert.Equal(t, ErrMetadataNotFound, r["v0.9.0"])
+
+	r, err = v.VerifyAll("~0.14.0")
+	assert.NoError(t, err)
+	assert.Len(t, r, 3)
+	assert.True(t, xerrors.Is(r["v0.14.1"], ErrChecksumMismatch))
+}
+
+func BenchmarkVerifyAll(b *testing.B) {
+	_, v, s := setupStub(b)
+
+	data := bytes.Repeat([]byte("fake-service"), 1<<20)
+
+	for i := 0; i < 8; i++ {
+		tag := fmt.Sprintf("v0.%d.0", i)
+		s.addRelease(tag, "fake-service-linux")
+		s.assets["/download/"+tag+"/fake-service-linux"] = data
+
+		_, err := v.DownloadRelease(tag, s.URL+"/download/"+tag+"/fake-service-linux")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, c := range []int{1, 4} {
+		b.Run(fmt.Sprintf("concurrency-%d", c), func(b *testing.B) {
+			v.options.Concurrency = c
+
+			b.SetBytes(int64(len(data) * 8))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				_, err := v.VerifyAll("")
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package gvm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// labelsFile is the name of the file in ReleasesPath which maps labels to tags
+const labelsFile = "labels.json"
+
+// ErrUnknownLabel is returned when a label has not been set with InstallAs
+var ErrUnknownLabel = xerrors.New("Unknown label")
+
+// InstallAs installs the latest release matching the constraint and records the label
+// so that GetByLabel returns the installed version. Installing with an existing label
+// re-points the label to the new version. The version is not downloaded again when it
+// is already installed. Returns ErrNoMatchingRelease when no release matches the constraint
+func (v *VersionsImpl) InstallAs(label, constraint string) (string, string, error) {
+	tag, fp, err := v.installLatest(constraint)
+	if err != nil {
+		return "", "", err
+	}
+
+	v.labelsMutex.Lock()
+	defer v.labelsMutex.Unlock()
+
+	labels, err := v.readLabels()
+	if err != nil {
+		return "", "", err
+	}
+
+	labels[label] = tag
+
+	err = v.writeLabels(labels)
+	if err != nil {
+		return "", "", err
+	}
+
+	return tag, fp, nil
+}
+
+// GetByLabel returns the tag and path of the version installed with the label
+// Returns ErrUnknownLabel when the label has not been set and ErrNotInstalled
+// when the version for the label has been removed
+func (v *VersionsImpl) GetByLabel(label string) (string, string, error) {
+	v.labelsMutex.Lock()
+	labels, err := v.readLabels()
+	v.labelsMutex.Unlock()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	tag, ok := labels[label]
+	if !ok {
+		return "", "", xerrors.Errorf("%w: %s", ErrUnknownLabel, label)
+	}
+
+	fp := v.exePath(tag)
+	if _, err := os.Stat(fp); err != nil {
+		return "", "", ErrNotInstalled
+	}
+
+	return tag, v.externalPath(fp), nil
+}
+
+func (v *VersionsImpl) readLabels() (map[string]string, error) {
+	labels := map[string]string{}
+
+	d, err := ioutil.ReadFile(path.Join(v.options.ReleasesPath, labelsFile))
+	if os.IsNotExist(err) {
+		return labels, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read labels: %w", err)
+	}
+
+	err = json.Unmarshal(d, &labels)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// writeLabels replaces the labels file using a temporary file so that readers
+// never see a partially written file
+func (v *VersionsImpl) writeLabels(labels map[string]string) error {
+	d, err := json.MarshalIndent(labels, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("Unable to encode labels: %w", err)
+	}
+
+	err = writeFileAtomic(path.Join(v.options.ReleasesPath, labelsFile), d, 0644)
+	if err != nil {
+		return xerrors.Errorf("Unable to write labels: %w", err)
+	}
+
+	return nil
+}
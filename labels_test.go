@@ -0,0 +1,72 @@
+package gvm
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestInstallAsInstallsAndRecordsLabel(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.13.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	tag, fp, err := v.InstallAs("lts", "~0.13.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.13.1", tag)
+	assert.Equal(t, path.Join(tmp, "v0.13.1", "fake-service-linux"), fp)
+	assert.FileExists(t, fp)
+
+	tag, lp, err := v.GetByLabel("lts")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.13.1", tag)
+	assert.Equal(t, fp, lp)
+}
+
+func TestInstallAsRepointsLabel(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.13.1", "fake-service-linux")
+
+	v.InstallAs("stable", "~0.13.0")
+	v.InstallAs("edge", "")
+
+	_, _, err := v.InstallAs("stable", "~0.14.0")
+	assert.NoError(t, err)
+
+	tag, _, err := v.GetByLabel("stable")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.2", tag)
+
+	tag, _, err = v.GetByLabel("edge")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.2", tag)
+}
+
+func TestInstallAsReturnsErrorWhenNoMatchingRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+
+	_, _, err := v.InstallAs("lts", "~1.0.0")
+	assert.True(t, xerrors.Is(err, ErrNoMatchingRelease))
+}
+
+func TestGetByLabelReturnsErrors(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+
+	_, _, err := v.GetByLabel("lts")
+	assert.True(t, xerrors.Is(err, ErrUnknownLabel))
+
+	v.InstallAs("lts", "")
+	os.RemoveAll(path.Join(tmp, "v0.14.2"))
+
+	_, _, err = v.GetByLabel("lts")
+	assert.Equal(t, ErrNotInstalled, err)
+}
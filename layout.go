@@ -0,0 +1,155 @@
+package gvm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+// LayoutKind defines how installed versions are arranged in ReleasesPath
+type LayoutKind int
+
+const (
+	// LayoutFlat installs each version to ReleasesPath/<tag>
+	LayoutFlat LayoutKind = iota
+	// LayoutPerPlatform installs each version to ReleasesPath/<goos>_<goarch>/<tag>
+	// allowing versions for multiple platforms to share a ReleasesPath
+	LayoutPerPlatform
+)
+
+// MigrateLayout moves the installed versions for the configured GOOS and GOARCH from
+// the current layout to the target layout and returns the tags which were moved.
+// Metadata stored in the version folder moves with the version and the current
+// version pointer references the tag so neither needs rewriting, the executable in
+// ReleasesPath/bin is placed again as it references the version folder.
+// Versions which already exist in the target layout are not moved so it is
+// safe to run the migration multiple times
+func (v *VersionsImpl) MigrateLayout(target LayoutKind) ([]string, error) {
+	migrated, err := v.migrateLayout(target)
+	if err != nil {
+		return migrated, err
+	}
+
+	current, err := v.GetCurrent()
+	if err != nil {
+		return migrated, err
+	}
+
+	if current == "" {
+		return migrated, nil
+	}
+
+	if _, err := os.Stat(v.exePath(current)); err != nil {
+		return migrated, nil
+	}
+
+	err = v.activate(current)
+	if err != nil {
+		return migrated, err
+	}
+
+	return migrated, nil
+}
+
+// migrateLayout moves the version folders to the target layout and sets it as the configured layout
+func (v *VersionsImpl) migrateLayout(target LayoutKind) ([]string, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if target == v.options.Layout {
+		return []string{}, nil
+	}
+
+	from := v.layoutRoot(v.options.Layout)
+	to := v.layoutRoot(target)
+
+	files, err := ioutil.ReadDir(from)
+	if os.IsNotExist(err) {
+		v.options.Layout = target
+		return []string{}, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to list releases: %w", err)
+	}
+
+	err = os.MkdirAll(to, os.ModePerm)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to create releases folder: %w", err)
+	}
+
+	migrated := []string{}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+
+		if _, err := v.parseVersion(f.Name()); err != nil {
+			continue
+		}
+
+		dest := path.Join(to, f.Name())
+		if _, err := os.Stat(dest); err == nil {
+			continue
+		}
+
+		err = os.Rename(path.Join(from, f.Name()), dest)
+		if err != nil {
+			return migrated, xerrors.Errorf("Unable to migrate version %s: %w", f.Name(), err)
+		}
+
+		migrated = append(migrated, f.Name())
+	}
+
+	sort.Strings(migrated)
+	v.options.Layout = target
+
+	return migrated, nil
+}
+
+// DownloadReleaseForTarget downloads and uncompresses the release for the given GOOS and GOARCH
+// rather than the configured platform. The release is installed using LayoutPerPlatform
+// to ReleasesPath/<goos>_<goarch>/<tag> so that it does not replace a version installed for
+// the configured platform. AssetNameFunc and ExeNameFunc are called with the target platform
+func (v *VersionsImpl) DownloadReleaseForTarget(tag, url, goos, goarch string) (string, error) {
+	return v.forTarget(goos, goarch).DownloadRelease(tag, url)
+}
+
+// forTarget returns a copy of the Versions for the given platform using LayoutPerPlatform,
+// versions installed for another platform are never set as the current version. Metadata is
+// stored in the target version folders as MetadataStore is keyed by tag and would replace
+// the metadata of the same version installed for the configured platform
+func (v *VersionsImpl) forTarget(goos, goarch string) *VersionsImpl {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	o := v.options
+	o.GOOS = goos
+	o.GOARCH = goarch
+	o.Layout = LayoutPerPlatform
+	o.SetCurrentOnInstall = false
+	o.MetadataStore = nil
+
+	return &VersionsImpl{options: o, client: v.client, presets: v.presets, cache: newReleaseCache(), throttle: v.throttle}
+}
+
+// installRoot returns the folder which contains the version folders for the configured layout
+func (v *VersionsImpl) installRoot() string {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.layoutRoot(v.options.Layout)
+}
+
+// layoutRoot returns the folder which contains the version folders for the given layout
+func (v *VersionsImpl) layoutRoot(l LayoutKind) string {
+	if l == LayoutPerPlatform {
+		return path.Join(v.options.ReleasesPath, fmt.Sprintf("%s_%s", v.options.GOOS, v.options.GOARCH))
+	}
+
+	return v.options.ReleasesPath
+}
@@ -0,0 +1,156 @@
+package gvm
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadReleaseUsesPerPlatformLayout(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.Layout = LayoutPerPlatform
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "linux_x64", "v0.14.1", "fake-service-linux"), fp)
+	assert.FileExists(t, fp)
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Equal(t, fp, r["v0.14.1"])
+}
+
+func TestMigrateLayoutMovesFlatToPerPlatform(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	v.DownloadRelease("v0.14.2", s.URL+"/download/v0.14.2/fake-service-linux")
+	v.SetCurrent("v0.14.2")
+
+	tags, err := v.MigrateLayout(LayoutPerPlatform)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.14.1", "v0.14.2"}, tags)
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+	assert.FileExists(t, path.Join(tmp, "linux_x64", "v0.14.1", "fake-service-linux"))
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(tmp, "linux_x64", "v0.14.2", "fake-service-linux"), r["v0.14.2"])
+
+	m, err := v.metadataStore().Get("v0.14.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.2", m.Tag)
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.2", c)
+}
+
+func TestMigrateLayoutUpdatesActiveExecutable(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	err = v.SetCurrent("v0.14.1")
+	assert.NoError(t, err)
+
+	_, err = v.MigrateLayout(LayoutPerPlatform)
+	assert.NoError(t, err)
+
+	target, err := filepath.EvalSymlinks(path.Join(tmp, "bin", "fake-service"))
+	assert.NoError(t, err)
+
+	expected, _ := filepath.EvalSymlinks(path.Join(tmp, "linux_x64", "v0.14.1", "fake-service-linux"))
+	assert.Equal(t, expected, target)
+}
+
+func TestMigrateLayoutIsIdempotent(t *testing.T) {
+	tmp, v := setup(t)
+
+	os.MkdirAll(path.Join(tmp, "v0.14.1"), os.ModePerm)
+	os.Create(path.Join(tmp, "v0.14.1", "fake-service-linux"))
+
+	tags, err := v.MigrateLayout(LayoutPerPlatform)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v0.14.1"}, tags)
+
+	tags, err = v.MigrateLayout(LayoutPerPlatform)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 0)
+
+	// a second instance still configured for the flat layout finds nothing to move
+	v.options.Layout = LayoutFlat
+	tags, err = v.MigrateLayout(LayoutPerPlatform)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 0)
+	assert.FileExists(t, path.Join(tmp, "linux_x64", "v0.14.1", "fake-service-linux"))
+}
+
+func TestDownloadReleaseForTargetInstallsToTargetFolder(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-osx")
+
+	fp, err := v.DownloadReleaseForTarget("v0.14.1", s.URL+"/download/v0.14.1/fake-service-osx", "darwin", "arm64")
+	assert.NoError(t, err)
+
+	assert.Equal(t, path.Join(tmp, "darwin_arm64", "v0.14.1", "fake-service-osx"), fp)
+	assert.FileExists(t, fp)
+
+	// the version for the configured platform is not installed
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Len(t, r, 0)
+}
+
+func TestDownloadReleaseForTargetDoesNotChangeCurrentVersion(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.SetCurrentOnInstall = true
+	s.addRelease("v0.14.2", "fake-service-linux", "fake-service-osx")
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	_, err = v.DownloadReleaseForTarget("v0.14.2", s.URL+"/download/v0.14.2/fake-service-osx", "darwin", "arm64")
+	assert.NoError(t, err)
+
+	c, err := v.GetCurrent()
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.14.1", c)
+
+	target, err := filepath.EvalSymlinks(path.Join(tmp, "bin", "fake-service"))
+	assert.NoError(t, err)
+
+	expected, _ := filepath.EvalSymlinks(fp)
+	assert.Equal(t, expected, target)
+	assert.NoFileExists(t, path.Join(tmp, "bin", "fake-service-osx"))
+}
+
+func TestDownloadReleaseForTargetDoesNotReplaceMetadata(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	v.options.MetadataStore = NewFileMetadataStore(path.Join(tmp, ".metadata", "index.json"))
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-osx")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	_, err = v.DownloadReleaseForTarget("v0.14.1", s.URL+"/download/v0.14.1/fake-service-osx", "darwin", "arm64")
+	assert.NoError(t, err)
+
+	m, err := v.metadataStore().Get("v0.14.1")
+	assert.NoError(t, err)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", m.URL)
+
+	err = v.VerifyIntegrity("v0.14.1")
+	assert.NoError(t, err)
+}
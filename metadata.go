@@ -0,0 +1,256 @@
+package gvm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrMetadataNotFound is returned when there is no metadata for a version
+var ErrMetadataNotFound = xerrors.New("Metadata not found")
+
+// Metadata is the information recorded when a version is installed
+type Metadata struct {
+	Tag         string    `json:"tag"`
+	URL         string    `json:"url"`
+	SHA256      string    `json:"sha256"`
+	InstalledAt time.Time `json:"installed_at"`
+	// Commit is the SHA the release tag referenced when the version was installed
+	Commit string `json:"commit,omitempty"`
+}
+
+// MetadataStore defines the methods for persisting Metadata for installed versions
+type MetadataStore interface {
+	// Get returns the metadata for the given tag
+	// returns ErrMetadataNotFound when no metadata exists
+	Get(tag string) (*Metadata, error)
+	// Set stores the metadata, replacing any existing metadata for the tag
+	Set(m Metadata) error
+	// Delete removes the metadata for the given tag
+	Delete(tag string) error
+	// List returns the metadata for all versions ordered by tag
+	List() ([]Metadata, error)
+}
+
+// SidecarMetadataStore stores the metadata for each version in a metadata.json
+// file inside the version folder
+type SidecarMetadataStore struct {
+	releasesPath string
+}
+
+// NewSidecarMetadataStore creates a SidecarMetadataStore for the given releases path
+func NewSidecarMetadataStore(releasesPath string) *SidecarMetadataStore {
+	return &SidecarMetadataStore{releasesPath}
+}
+
+// Get returns the metadata from the metadata.json in the version folder
+func (s *SidecarMetadataStore) Get(tag string) (*Metadata, error) {
+	d, err := ioutil.ReadFile(s.file(tag))
+	if os.IsNotExist(err) {
+		return nil, ErrMetadataNotFound
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read metadata: %w", err)
+	}
+
+	m := &Metadata{}
+	err = json.Unmarshal(d, m)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse metadata: %w", err)
+	}
+
+	return m, nil
+}
+
+// Set writes the metadata to the metadata.json in the version folder
+func (s *SidecarMetadataStore) Set(m Metadata) error {
+	err := os.MkdirAll(path.Join(s.releasesPath, m.Tag), os.ModePerm)
+	if err != nil {
+		return xerrors.Errorf("Unable to create version folder: %w", err)
+	}
+
+	d, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("Unable to encode metadata: %w", err)
+	}
+
+	err = ioutil.WriteFile(s.file(m.Tag), d, 0644)
+	if err != nil {
+		return xerrors.Errorf("Unable to write metadata: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the metadata.json from the version folder
+func (s *SidecarMetadataStore) Delete(tag string) error {
+	err := os.Remove(s.file(tag))
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("Unable to remove metadata: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the metadata for every version folder containing a metadata.json
+func (s *SidecarMetadataStore) List() ([]Metadata, error) {
+	files, err := ioutil.ReadDir(s.releasesPath)
+	if os.IsNotExist(err) {
+		return []Metadata{}, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to list releases: %w", err)
+	}
+
+	md := []Metadata{}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+
+		m, err := s.Get(f.Name())
+		if err == ErrMetadataNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		md = append(md, *m)
+	}
+
+	return md, nil
+}
+
+func (s *SidecarMetadataStore) file(tag string) string {
+	return path.Join(s.releasesPath, tag, "metadata.json")
+}
+
+// FileMetadataStore stores the metadata for all versions in a single JSON index file
+type FileMetadataStore struct {
+	filePath string
+	mutex    sync.Mutex
+}
+
+// NewFileMetadataStore creates a FileMetadataStore which uses the index file at the given path
+func NewFileMetadataStore(filePath string) *FileMetadataStore {
+	return &FileMetadataStore{filePath: filePath}
+}
+
+// Get returns the metadata for the tag from the index
+func (s *FileMetadataStore) Get(tag string) (*Metadata, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := idx[tag]
+	if !ok {
+		return nil, ErrMetadataNotFound
+	}
+
+	return &m, nil
+}
+
+// Set adds the metadata to the index
+func (s *FileMetadataStore) Set(m Metadata) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	idx[m.Tag] = m
+
+	return s.write(idx)
+}
+
+// Delete removes the metadata for the tag from the index
+func (s *FileMetadataStore) Delete(tag string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	delete(idx, tag)
+
+	return s.write(idx)
+}
+
+// List returns all the metadata in the index
+func (s *FileMetadataStore) List() ([]Metadata, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	idx, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	md := []Metadata{}
+	for _, m := range idx {
+		md = append(md, m)
+	}
+
+	sort.Slice(md, func(i, j int) bool { return md[i].Tag < md[j].Tag })
+
+	return md, nil
+}
+
+func (s *FileMetadataStore) read() (map[string]Metadata, error) {
+	idx := map[string]Metadata{}
+
+	d, err := ioutil.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to read metadata index: %w", err)
+	}
+
+	err = json.Unmarshal(d, &idx)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to parse metadata index: %w", err)
+	}
+
+	return idx, nil
+}
+
+// write replaces the index file using a temporary file so that readers never
+// see a partially written index
+func (s *FileMetadataStore) write(idx map[string]Metadata) error {
+	err := os.MkdirAll(path.Dir(s.filePath), os.ModePerm)
+	if err != nil {
+		return xerrors.Errorf("Unable to create metadata folder: %w", err)
+	}
+
+	d, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("Unable to encode metadata index: %w", err)
+	}
+
+	err = writeFileAtomic(s.filePath, d, 0644)
+	if err != nil {
+		return xerrors.Errorf("Unable to write metadata index: %w", err)
+	}
+
+	return nil
+}
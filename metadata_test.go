@@ -0,0 +1,119 @@
+package gvm
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metadataStores returns each MetadataStore implementation so that
+// tests can run the same scenarios against all of them
+func metadataStores(tmp string) map[string]MetadataStore {
+	return map[string]MetadataStore{
+		"sidecar": NewSidecarMetadataStore(tmp),
+		"file":    NewFileMetadataStore(path.Join(tmp, ".metadata", "index.json")),
+	}
+}
+
+func TestMetadataStoreSetsAndGets(t *testing.T) {
+	tmp, _ := setup(t)
+
+	for name, ms := range metadataStores(tmp) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().UTC().Truncate(time.Second)
+			err := ms.Set(Metadata{Tag: "v0.14.1", URL: "https://example.com", SHA256: "abc", InstalledAt: now})
+			assert.NoError(t, err)
+
+			m, err := ms.Get("v0.14.1")
+			assert.NoError(t, err)
+
+			assert.Equal(t, "https://example.com", m.URL)
+			assert.Equal(t, "abc", m.SHA256)
+			assert.Equal(t, now, m.InstalledAt.UTC())
+		})
+	}
+}
+
+func TestMetadataStoreReturnsNotFound(t *testing.T) {
+	tmp, _ := setup(t)
+
+	for name, ms := range metadataStores(tmp) {
+		t.Run(name, func(t *testing.T) {
+			_, err := ms.Get("v0.1.0")
+			assert.Equal(t, ErrMetadataNotFound, err)
+		})
+	}
+}
+
+func TestMetadataStoreDeletes(t *testing.T) {
+	tmp, _ := setup(t)
+
+	for name, ms := range metadataStores(tmp) {
+		t.Run(name, func(t *testing.T) {
+			ms.Set(Metadata{Tag: "v0.14.1"})
+
+			err := ms.Delete("v0.14.1")
+			assert.NoError(t, err)
+
+			_, err = ms.Get("v0.14.1")
+			assert.Equal(t, ErrMetadataNotFound, err)
+		})
+	}
+}
+
+func TestMetadataStoreLists(t *testing.T) {
+	tmp, _ := setup(t)
+
+	for name, ms := range metadataStores(tmp) {
+		t.Run(name, func(t *testing.T) {
+			ms.Set(Metadata{Tag: "v0.14.2"})
+			ms.Set(Metadata{Tag: "v0.14.1"})
+
+			md, err := ms.List()
+			assert.NoError(t, err)
+
+			assert.Len(t, md, 2)
+			assert.Equal(t, "v0.14.1", md[0].Tag)
+			assert.Equal(t, "v0.14.2", md[1].Tag)
+		})
+	}
+}
+
+func TestDownloadReleaseRecordsMetadata(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	for name, ms := range metadataStores(tmp) {
+		t.Run(name, func(t *testing.T) {
+			v.options.MetadataStore = ms
+
+			_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+			assert.NoError(t, err)
+
+			m, err := ms.Get("v0.14.1")
+			assert.NoError(t, err)
+
+			assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", m.URL)
+			// hex encoded sha256 of the downloaded file
+			assert.Len(t, m.SHA256, 64)
+			assert.False(t, m.InstalledAt.IsZero())
+		})
+	}
+}
+
+func TestListInstalledIgnoresMetadataIndex(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.MetadataStore = NewFileMetadataStore(path.Join(tmp, ".metadata", "index.json"))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Contains(t, r, "v0.14.1")
+}
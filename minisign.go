@@ -0,0 +1,144 @@
+package gvm
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jedisct1/go-minisign"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/xerrors"
+)
+
+// ErrInvalidSignature is returned when the minisign signature for an asset can not be verified
+var ErrInvalidSignature = xerrors.New("Invalid minisign signature")
+
+// minisignExt is the extension of the signature asset published alongside each asset
+const minisignExt = ".minisig"
+
+const (
+	// minisignLegacy signatures sign the content of the file
+	minisignLegacy = "Ed"
+	// minisignPrehashed signatures sign the BLAKE2b-512 hash of the file
+	minisignPrehashed = "ED"
+)
+
+// verifyMinisign downloads the minisign signature for the asset from the asset URL with the
+// .minisig extension into dir and verifies the asset with MinisignPublicKey. Assets are not
+// verified when MinisignPublicKey is not set
+func (v *VersionsImpl) verifyMinisign(dir, src, asset string) error {
+	if v.options.MinisignPublicKey == "" {
+		return nil
+	}
+
+	su, err := signatureURL(src)
+	if err != nil {
+		return err
+	}
+
+	sig, _, err := v.fetch(context.Background(), dir, su, nil)
+	if err != nil {
+		return xerrors.Errorf("Unable to download signature: %w", err)
+	}
+	defer os.Remove(sig)
+
+	d, err := ioutil.ReadFile(sig)
+	if err != nil {
+		return xerrors.Errorf("Unable to read signature: %w", err)
+	}
+
+	return verifyMinisign(v.options.MinisignPublicKey, asset, d)
+}
+
+// signatureURL returns the URL of the minisign signature for the asset at src, the
+// extension is added to the path so that it comes before any query string
+func signatureURL(src string) (string, error) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", xerrors.Errorf("Invalid URL %s: %w", src, err)
+	}
+
+	u.Path += minisignExt
+	if u.RawPath != "" {
+		u.RawPath += minisignExt
+	}
+
+	return u.String(), nil
+}
+
+// verifyMinisign verifies the file fp against the minisign signature sig using the
+// base64 encoded public key or the content of a minisign public key file
+func verifyMinisign(publicKey, fp string, sig []byte) error {
+	pub, err := parseMinisignKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	s, err := minisign.DecodeSignature(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return xerrors.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+
+	d, err := minisignMessage(fp, &s)
+	if err != nil {
+		return xerrors.Errorf("Unable to read downloaded file: %w", err)
+	}
+
+	ok, err := pub.Verify(d, s)
+	if err != nil {
+		return xerrors.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+
+	if !ok {
+		return xerrors.Errorf("%w: signature does not match the downloaded file", ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// minisignMessage returns the message signed by s for the file fp. Legacy signatures sign
+// the content of the file, prehashed signatures sign the BLAKE2b-512 hash of the file which
+// is streamed so that large assets are not read into memory. The algorithm of a prehashed
+// signature is changed to legacy so that Verify checks the hash without hashing it again,
+// the global signature does not cover the algorithm so verification is unchanged
+func minisignMessage(fp string, s *minisign.Signature) ([]byte, error) {
+	if string(s.SignatureAlgorithm[:]) != minisignPrehashed {
+		return ioutil.ReadFile(fp)
+	}
+
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h, err := blake2b.New512(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+
+	copy(s.SignatureAlgorithm[:], minisignLegacy)
+
+	return h.Sum(nil), nil
+}
+
+// parseMinisignKey returns the public key from a base64 encoded minisign public key,
+// the untrusted comment line of a public key file is ignored
+func parseMinisignKey(key string) (minisign.PublicKey, error) {
+	lines := strings.Split(strings.TrimSpace(key), "\n")
+
+	pub, err := minisign.NewPublicKey(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return pub, xerrors.Errorf("Invalid minisign public key: %w", err)
+	}
+
+	return pub, nil
+}
@@ -0,0 +1,202 @@
+package gvm
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/xerrors"
+)
+
+// minisignToolKey and the signatures below were created by the minisign tool for a
+// file containing "test"
+const minisignToolKey = "untrusted comment: minisign public key 1F62A0F1842B4E81F\n" +
+	"RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+
+var minisignToolSignatures = map[string]string{
+	minisignLegacy: "untrusted comment: signature from minisign secret key\n" +
+		"RWQf6LRCGA9i59SLOFxz6NxvASXDJeRtuZykwQepbDEGt87ig1BNpWaVWuNrm73YiIiJbq71Wi+dP9eKL8OC351vwIasSSbXxwA=\n" +
+		"trusted comment: timestamp:1635442742\tfile:test\n" +
+		"0YteLgV960ia80vnA/fHbvkyjl/IoP/HNOCaZfrF0CdhAlp7ok+Tpkya+VpWPX5C/Is3q8a/kEDSY7fBmmgJCg==\n",
+	minisignPrehashed: "untrusted comment: signature from minisign secret key\n" +
+		"RUQf6LRCGA9i559r3g7V1qNyJDApGip8MfqcadIgT9CuhV3EMhHoN1mGTkUidF/z7SrlQgXdy8ofjb7bNJJylDOocrCo8KLzZwo=\n" +
+		"trusted comment: timestamp:1635443258\tfile:test\thashed\n" +
+		"/cj37GK60vryibFn+ftOgbCvW9NKhKYgjVpFFQUcWPAnjO23wrvVDTt7cloNC06maoBli9q6qwZDXXoaxweICQ==\n",
+}
+
+// minisignKey is a minisign key pair used to sign test assets
+type minisignKey struct {
+	id   []byte
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newMinisignKey(t *testing.T) *minisignKey {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	return &minisignKey{id: []byte("12345678"), pub: pub, priv: priv}
+}
+
+// publicKey returns the content of the minisign public key file
+func (k *minisignKey) publicKey() string {
+	d := append([]byte(minisignLegacy), k.id...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(append(d, k.pub...))
+}
+
+// sign returns the minisign signature file for the data using the given algorithm
+func (k *minisignKey) sign(algo string, data []byte) []byte {
+	msg := data
+	if algo == minisignPrehashed {
+		sum := blake2b.Sum512(data)
+		msg = sum[:]
+	}
+
+	sig := ed25519.Sign(k.priv, msg)
+	comment := "timestamp:1600000000"
+	global := ed25519.Sign(k.priv, append(append([]byte{}, sig...), comment...))
+
+	s := append(append([]byte(algo), k.id...), sig...)
+
+	return []byte(fmt.Sprintf(
+		"untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(s),
+		comment,
+		base64.StdEncoding.EncodeToString(global),
+	))
+}
+
+func TestDownloadVerifiesMinisignSignature(t *testing.T) {
+	for _, algo := range []string{minisignLegacy, minisignPrehashed} {
+		_, v, s := setupStub(t)
+		k := newMinisignKey(t)
+		v.options.MinisignPublicKey = k.publicKey()
+		s.addRelease("v0.14.1", "fake-service-linux")
+		s.assets["/download/v0.14.1/fake-service-linux.minisig"] = k.sign(algo, []byte("fake-service-linux"))
+
+		fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+		assert.NoError(t, err, algo)
+		assert.FileExists(t, fp)
+	}
+}
+
+func TestDownloadVerifiesMinisignSignatureForURLWithQuery(t *testing.T) {
+	_, v, s := setupStub(t)
+	k := newMinisignKey(t)
+	v.options.MinisignPublicKey = k.publicKey()
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.assets["/download/v0.14.1/fake-service-linux.minisig"] = k.sign(minisignPrehashed, []byte("fake-service-linux"))
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux?token=abc")
+	assert.NoError(t, err)
+	assert.FileExists(t, fp)
+}
+
+func TestSignatureURLAddsExtensionBeforeQuery(t *testing.T) {
+	su, err := signatureURL("https://example.com/download/tool.tar.gz?token=abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/download/tool.tar.gz.minisig?token=abc", su)
+}
+
+func TestDownloadReturnsErrorWhenMinisignSignatureDoesNotMatch(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	k := newMinisignKey(t)
+	v.options.MinisignPublicKey = k.publicKey()
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.assets["/download/v0.14.1/fake-service-linux.minisig"] = k.sign(minisignPrehashed, []byte("tampered"))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrInvalidSignature))
+
+	assert.NoFileExists(t, path.Join(tmp, "v0.14.1", "fake-service-linux"))
+}
+
+func TestDownloadReturnsErrorWhenMinisignKeyDoesNotMatch(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.MinisignPublicKey = newMinisignKey(t).publicKey()
+	other := newMinisignKey(t)
+	other.id = []byte("87654321")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.assets["/download/v0.14.1/fake-service-linux.minisig"] = other.sign(minisignPrehashed, []byte("fake-service-linux"))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrInvalidSignature))
+}
+
+func TestDownloadReturnsErrorWhenMinisignSignatureMissing(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.MinisignPublicKey = newMinisignKey(t).publicKey()
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.Error(t, err)
+}
+
+func TestVerifyMinisignReturnsErrorWhenTrustedCommentChanged(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	k := newMinisignKey(t)
+	v.options.MinisignPublicKey = k.publicKey()
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	fp, _, err := v.fetch(context.Background(), tmp, s.URL+"/download/v0.14.1/fake-service-linux", nil)
+	assert.NoError(t, err)
+
+	sig := []byte(strings.Replace(string(k.sign(minisignPrehashed, []byte("fake-service-linux"))), "timestamp", "changed", 1))
+
+	err = verifyMinisign(k.publicKey(), fp, sig)
+	assert.True(t, xerrors.Is(err, ErrInvalidSignature))
+}
+
+func TestDownloadQuarantinesWhenMinisignSignatureDoesNotMatch(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	k := newMinisignKey(t)
+	v.options.MinisignPublicKey = k.publicKey()
+	v.options.QuarantineOnFailure = true
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.assets["/download/v0.14.1/fake-service-linux.minisig"] = k.sign(minisignLegacy, []byte("tampered"))
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrInvalidSignature))
+
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+
+	q, err := v.ListQuarantine()
+	assert.NoError(t, err)
+
+	assert.Len(t, q, 1)
+	assert.Equal(t, "v0.14.1", q[0].Tag)
+	assert.Contains(t, q[0].Reason, ErrInvalidSignature.Error())
+	assert.FileExists(t, q[0].Path)
+}
+
+func TestVerifyMinisignWithMinisignToolSignatures(t *testing.T) {
+	tmp, _ := setup(t)
+	fp := path.Join(tmp, "test")
+	err := ioutil.WriteFile(fp, []byte("test"), os.ModePerm)
+	assert.NoError(t, err)
+
+	for algo, sig := range minisignToolSignatures {
+		err := verifyMinisign(minisignToolKey, fp, []byte(sig))
+		assert.NoError(t, err, algo)
+	}
+}
+
+func TestVerifyMinisignWithMinisignToolSignaturesReturnsErrorWhenFileChanged(t *testing.T) {
+	tmp, _ := setup(t)
+	fp := path.Join(tmp, "test")
+	err := ioutil.WriteFile(fp, []byte("tampered"), os.ModePerm)
+	assert.NoError(t, err)
+
+	for algo, sig := range minisignToolSignatures {
+		err := verifyMinisign(minisignToolKey, fp, []byte(sig))
+		assert.True(t, xerrors.Is(err, ErrInvalidSignature), algo)
+	}
+}
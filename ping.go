@@ -0,0 +1,87 @@
+package gvm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+// ErrDNSFailure is returned by Ping when the GitHub API host name can not be resolved
+var ErrDNSFailure = xerrors.New("Unable to resolve the GitHub API host")
+
+// ErrTLSFailure is returned by Ping when the TLS connection to GitHub can not be established
+// e.g. a proxy is intercepting connections with a certificate which is not trusted
+var ErrTLSFailure = xerrors.New("Unable to establish a secure connection to GitHub")
+
+// ErrUnauthorized is returned by Ping when GitHub rejects the credentials used for the request
+// or the credentials do not have access to the repository
+var ErrUnauthorized = xerrors.New("GitHub rejected the request as unauthorized")
+
+// ErrRateLimited is returned by Ping when the GitHub rate limit has been exceeded
+var ErrRateLimited = xerrors.New("GitHub rate limit exceeded")
+
+// Ping checks GitHub can be reached by getting the repository, the request is not retried.
+// Failures are classified as ErrDNSFailure, ErrTLSFailure, ErrUnauthorized or ErrRateLimited
+// allowing the cause to be reported, other failures are returned unclassified
+func (v *VersionsImpl) Ping() error {
+	if v.options.Frozen {
+		return ErrFrozen
+	}
+
+	_, resp, err := v.client.Repositories.Get(context.Background(), v.options.Organization, v.options.Repo)
+	if err == nil {
+		return nil
+	}
+
+	if cause := pingFailure(resp, err); cause != nil {
+		return xerrors.Errorf("%w: %v", cause, err)
+	}
+
+	return xerrors.Errorf("Unable to reach GitHub: %w", err)
+}
+
+// pingFailure returns the sentinel error for the cause of a failed request, nil when
+// the cause is not known
+func pingFailure(resp *github.Response, err error) error {
+	var dns *net.DNSError
+	if xerrors.As(err, &dns) {
+		return ErrDNSFailure
+	}
+
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var header tls.RecordHeaderError
+	if xerrors.As(err, &unknownAuthority) || xerrors.As(err, &hostname) || xerrors.As(err, &invalid) || xerrors.As(err, &header) {
+		return ErrTLSFailure
+	}
+
+	var rate *github.RateLimitError
+	if xerrors.As(err, &rate) {
+		return ErrRateLimited
+	}
+
+	if _, ok := secondaryRateLimit(resp, err); ok {
+		return ErrRateLimited
+	}
+
+	if resp != nil && resp.Response != nil {
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			return ErrRateLimited
+		}
+
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrUnauthorized
+		case http.StatusTooManyRequests:
+			return ErrRateLimited
+		}
+	}
+
+	return nil
+}
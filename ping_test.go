@@ -0,0 +1,89 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestPingReturnsNilWhenGitHubReachable(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"name": "fake-service"}`))
+	})
+
+	assert.NoError(t, v.Ping())
+}
+
+func TestPingReturnsErrUnauthorized(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service", func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusUnauthorized)
+		rw.Write([]byte(`{"message": "Bad credentials"}`))
+	})
+
+	err := v.Ping()
+	assert.True(t, xerrors.Is(err, ErrUnauthorized), err)
+}
+
+func TestPingReturnsErrRateLimited(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("X-RateLimit-Limit", "60")
+		rw.Header().Set("X-RateLimit-Remaining", "0")
+		rw.Header().Set("X-RateLimit-Reset", "1600000000")
+		rw.WriteHeader(http.StatusForbidden)
+		rw.Write([]byte(`{"message": "API rate limit exceeded for 127.0.0.1."}`))
+	})
+
+	err := v.Ping()
+	assert.True(t, xerrors.Is(err, ErrRateLimited), err)
+}
+
+func TestPingReturnsErrRateLimitedForSecondaryRateLimit(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.mux.HandleFunc("/repos/nicholasjackson/fake-service", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Retry-After", "60")
+		rw.WriteHeader(http.StatusForbidden)
+		rw.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+	})
+
+	err := v.Ping()
+	assert.True(t, xerrors.Is(err, ErrRateLimited), err)
+}
+
+func TestPingReturnsErrTLSFailure(t *testing.T) {
+	_, v := setup(t)
+	s := httptest.NewUnstartedServer(http.NotFoundHandler())
+	s.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	s.StartTLS()
+	t.Cleanup(s.Close)
+
+	// the certificate of the test server is not trusted by the client
+	v.client.BaseURL, _ = url.Parse(s.URL + "/")
+
+	err := v.Ping()
+	assert.True(t, xerrors.Is(err, ErrTLSFailure), err)
+}
+
+func TestPingReturnsErrDNSFailure(t *testing.T) {
+	_, v := setup(t)
+	v.client.BaseURL, _ = url.Parse("http://api.github.invalid/")
+
+	err := v.Ping()
+	assert.True(t, xerrors.Is(err, ErrDNSFailure), err)
+}
+
+func TestPingReturnsErrorWhenNotFound(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	err := v.Ping()
+	assert.Error(t, err)
+	assert.False(t, xerrors.Is(err, ErrUnauthorized))
+}
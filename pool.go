@@ -0,0 +1,35 @@
+package gvm
+
+import "sync"
+
+// parallel calls fn for each index from 0 to n-1 with at most concurrency calls running at
+// once, returning the error from each call at the same index. Batch operations store the result
+// of each call by index so that their results do not depend on the order the calls complete
+func parallel(n, concurrency int, fn func(i int) error) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, n)
+
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, concurrency)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[i] = fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+
+	return errs
+}
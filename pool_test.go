@@ -0,0 +1,74 @@
+package gvm
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelReturnsErrorsInIndexOrder(t *testing.T) {
+	results := make([]int, 20)
+
+	errs := parallel(len(results), 4, func(i int) error {
+		// complete in a random order
+		time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+
+		results[i] = i * 2
+		if i%3 == 0 {
+			return fmt.Errorf("failed %d", i)
+		}
+
+		return nil
+	})
+
+	assert.Len(t, errs, 20)
+	for i := range results {
+		assert.Equal(t, i*2, results[i])
+
+		if i%3 == 0 {
+			assert.EqualError(t, errs[i], fmt.Sprintf("failed %d", i))
+		} else {
+			assert.NoError(t, errs[i])
+		}
+	}
+}
+
+func TestParallelLimitsConcurrency(t *testing.T) {
+	mutex := sync.Mutex{}
+	running := 0
+	max := 0
+
+	parallel(20, 3, func(i int) error {
+		mutex.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+
+		return nil
+	})
+
+	assert.LessOrEqual(t, max, 3)
+}
+
+func TestParallelRunsSequentiallyWhenConcurrencyNotSet(t *testing.T) {
+	order := []int{}
+
+	parallel(5, 0, func(i int) error {
+		order = append(order, i)
+		return nil
+	})
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+}
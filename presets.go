@@ -0,0 +1,48 @@
+package gvm
+
+import (
+	"github.com/Masterminds/semver"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownPreset is returned when a preset has not been loaded
+var ErrUnknownPreset = xerrors.New("Unknown preset")
+
+// LoadPresets loads named semantic version constraints from a JSON or YAML document
+// e.g. {"lts": "~1.8.0", "edge": ">=2.0.0-0"}, loaded presets replace any existing presets
+func (v *VersionsImpl) LoadPresets(data []byte) error {
+	presets := map[string]string{}
+
+	err := yaml.Unmarshal(data, &presets)
+	if err != nil {
+		return xerrors.Errorf("Unable to parse presets: %w", err)
+	}
+
+	for name, c := range presets {
+		_, err := semver.NewConstraint(c)
+		if err != nil {
+			return xerrors.Errorf("Invalid sematic version constraint for preset %s: %w", name, err)
+		}
+	}
+
+	v.mutex.Lock()
+	v.presets = presets
+	v.mutex.Unlock()
+
+	return nil
+}
+
+// GetLatestForPreset returns the latest release matching the constraint for the named preset
+// Returns ErrUnknownPreset when the preset has not been loaded
+func (v *VersionsImpl) GetLatestForPreset(name string) (string, string, error) {
+	v.mutex.RLock()
+	c, ok := v.presets[name]
+	v.mutex.RUnlock()
+
+	if !ok {
+		return "", "", xerrors.Errorf("%w: %s", ErrUnknownPreset, name)
+	}
+
+	return v.GetLatestReleaseURL(c)
+}
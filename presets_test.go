@@ -0,0 +1,53 @@
+package gvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestGetLatestForPresetFromJSON(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v2.0.0", "fake-service-linux")
+	s.addRelease("v1.8.3", "fake-service-linux")
+	s.addRelease("v1.8.2", "fake-service-linux")
+
+	err := v.LoadPresets([]byte(`{"lts": "~1.8.0", "edge": ">=2.0.0-0"}`))
+	assert.NoError(t, err)
+
+	tag, url, err := v.GetLatestForPreset("lts")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.8.3", tag)
+	assert.Equal(t, s.URL+"/download/v1.8.3/fake-service-linux", url)
+
+	tag, _, err = v.GetLatestForPreset("edge")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0", tag)
+}
+
+func TestGetLatestForPresetFromYAML(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.8.3", "fake-service-linux")
+
+	err := v.LoadPresets([]byte("lts: ~1.8.0\n"))
+	assert.NoError(t, err)
+
+	tag, _, err := v.GetLatestForPreset("lts")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.8.3", tag)
+}
+
+func TestGetLatestForPresetReturnsErrorForUnknownPreset(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, _, err := v.GetLatestForPreset("lts")
+	assert.True(t, xerrors.Is(err, ErrUnknownPreset))
+}
+
+func TestLoadPresetsReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	err := v.LoadPresets([]byte(`{"lts": "abc"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package gvm
+
+import (
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+// InstallLatestAndPrune installs the latest release matching the constraint then removes the
+// installed versions matching the constraint other than the newest keep versions.
+// The current version and versions referenced by a label are never removed.
+// keep is always at least 1 so the newly installed version is not removed.
+// Returns the installed tag and the tags which were removed in ascending order
+func (v *VersionsImpl) InstallLatestAndPrune(constraint string, keep int) (string, []string, error) {
+	tag, _, err := v.installLatest(constraint)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if keep < 1 {
+		keep = 1
+	}
+
+	installed, err := v.ListInstalledVersions(constraint)
+	if err != nil {
+		return tag, nil, err
+	}
+
+	protected, err := v.protectedVersions()
+	if err != nil {
+		return tag, nil, err
+	}
+
+	removed := []string{}
+	sorted := v.SortMapKeys(installed, true)
+	for i := len(sorted) - 1; i >= keep; i-- {
+		t := sorted[i]
+		if protected[t] || t == tag {
+			continue
+		}
+
+		err := v.remove(t)
+		if err != nil {
+			return tag, removed, err
+		}
+
+		removed = append(removed, t)
+	}
+
+	return tag, removed, nil
+}
+
+// protectedVersions returns the tags of the current version and the labelled versions
+func (v *VersionsImpl) protectedVersions() (map[string]bool, error) {
+	protected := map[string]bool{}
+
+	current, err := v.GetCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	if current != "" {
+		protected[current] = true
+	}
+
+	v.labelsMutex.Lock()
+	labels, err := v.readLabels()
+	v.labelsMutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range labels {
+		protected[t] = true
+	}
+
+	return protected, nil
+}
+
+// remove deletes the folder and metadata for the installed version
+func (v *VersionsImpl) remove(tag string) error {
+	err := os.RemoveAll(v.versionDir(tag))
+	if err != nil {
+		return xerrors.Errorf("Unable to remove version %s: %w", tag, err)
+	}
+
+	return v.metadataStore().Delete(tag)
+}
@@ -0,0 +1,68 @@
+package gvm
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func setupPrune(t *testing.T) (string, *VersionsImpl, *stubGitHub) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.3", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.0", "fake-service-linux")
+
+	for _, tag := range []string{"v0.14.2", "v0.14.1", "v0.14.0"} {
+		_, err := v.DownloadRelease(tag, s.URL+"/download/"+tag+"/fake-service-linux")
+		assert.NoError(t, err)
+	}
+
+	return tmp, v, s
+}
+
+func TestInstallLatestAndPruneRemovesOlderVersions(t *testing.T) {
+	tmp, v, _ := setupPrune(t)
+
+	tag, removed, err := v.InstallLatestAndPrune("", 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.3", tag)
+	assert.Equal(t, []string{"v0.14.0", "v0.14.1"}, removed)
+	assert.FileExists(t, path.Join(tmp, "v0.14.3", "fake-service-linux"))
+	assert.FileExists(t, path.Join(tmp, "v0.14.2", "fake-service-linux"))
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.0"))
+}
+
+func TestInstallLatestAndPruneKeepsCurrentAndLabelledVersions(t *testing.T) {
+	tmp, v, _ := setupPrune(t)
+	v.SetCurrent("v0.14.0")
+	v.InstallAs("lts", ">=0.14.1, <0.14.2")
+
+	_, removed, err := v.InstallLatestAndPrune("", 1)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.14.2"}, removed)
+	assert.DirExists(t, path.Join(tmp, "v0.14.1"))
+	assert.DirExists(t, path.Join(tmp, "v0.14.0"))
+}
+
+func TestInstallLatestAndPruneOnlyRemovesVersionsMatchingConstraint(t *testing.T) {
+	tmp, v, _ := setupPrune(t)
+
+	_, removed, err := v.InstallLatestAndPrune(">0.14.0", 1)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v0.14.1", "v0.14.2"}, removed)
+	assert.DirExists(t, path.Join(tmp, "v0.14.0"))
+}
+
+func TestInstallLatestAndPruneReturnsErrorWhenNoMatchingRelease(t *testing.T) {
+	_, v, _ := setupPrune(t)
+
+	_, _, err := v.InstallLatestAndPrune("~1.0.0", 1)
+	assert.True(t, xerrors.Is(err, ErrNoMatchingRelease))
+}
@@ -0,0 +1,103 @@
+package gvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// quarantineFolder is the folder in ReleasesPath where assets which fail verification are kept
+const quarantineFolder = ".quarantine"
+
+// QuarantinedAsset describes a downloaded asset which failed verification
+type QuarantinedAsset struct {
+	Tag           string    `json:"tag"`
+	URL           string    `json:"url"`
+	Reason        string    `json:"reason"`
+	QuarantinedAt time.Time `json:"quarantined_at"`
+	// Path is the location of the quarantined asset
+	Path string `json:"path"`
+}
+
+// ListQuarantine returns the assets which have been quarantined
+func (v *VersionsImpl) ListQuarantine() ([]QuarantinedAsset, error) {
+	qf := path.Join(v.options.ReleasesPath, quarantineFolder)
+
+	files, err := ioutil.ReadDir(qf)
+	if os.IsNotExist(err) {
+		return []QuarantinedAsset{}, nil
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to list quarantine: %w", err)
+	}
+
+	assets := []QuarantinedAsset{}
+	for _, f := range files {
+		d, err := ioutil.ReadFile(path.Join(qf, f.Name(), "quarantine.json"))
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to read quarantine note: %w", err)
+		}
+
+		qa := QuarantinedAsset{}
+		err = json.Unmarshal(d, &qa)
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to parse quarantine note: %w", err)
+		}
+
+		qa.Path = path.Join(qf, f.Name(), qa.Path)
+		assets = append(assets, qa)
+	}
+
+	return assets, nil
+}
+
+// ClearQuarantine removes all quarantined assets
+func (v *VersionsImpl) ClearQuarantine() error {
+	err := os.RemoveAll(path.Join(v.options.ReleasesPath, quarantineFolder))
+	if err != nil {
+		return xerrors.Errorf("Unable to clear quarantine: %w", err)
+	}
+
+	return nil
+}
+
+// quarantine moves an asset which failed verification to the quarantine folder
+// along with a note containing the reason it failed
+func (v *VersionsImpl) quarantine(tag, url, asset string, reason error) error {
+	now := time.Now()
+	dir := path.Join(v.options.ReleasesPath, quarantineFolder, fmt.Sprintf("%s-%d", tag, now.UnixNano()))
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return xerrors.Errorf("Unable to create quarantine folder: %w", err)
+	}
+
+	err = os.Rename(asset, path.Join(dir, path.Base(asset)))
+	if err != nil {
+		return xerrors.Errorf("Unable to quarantine asset: %w", err)
+	}
+
+	d, err := json.MarshalIndent(QuarantinedAsset{
+		Tag:           tag,
+		URL:           url,
+		Reason:        reason.Error(),
+		QuarantinedAt: now,
+		Path:          path.Base(asset),
+	}, "", "  ")
+	if err != nil {
+		return xerrors.Errorf("Unable to encode quarantine note: %w", err)
+	}
+
+	err = ioutil.WriteFile(path.Join(dir, "quarantine.json"), d, 0644)
+	if err != nil {
+		return xerrors.Errorf("Unable to write quarantine note: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package gvm
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func setupChecksumFailure(t *testing.T) (string, *VersionsImpl, *stubGitHub) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		return "0000000000000000000000000000000000000000000000000000000000000000"
+	}
+
+	return tmp, v, s
+}
+
+func TestDownloadReleaseDeletesOnChecksumFailure(t *testing.T) {
+	tmp, v, s := setupChecksumFailure(t)
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrChecksumMismatch))
+
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+
+	q, err := v.ListQuarantine()
+	assert.NoError(t, err)
+	assert.Len(t, q, 0)
+}
+
+func TestDownloadReleaseQuarantinesOnChecksumFailure(t *testing.T) {
+	tmp, v, s := setupChecksumFailure(t)
+	v.options.QuarantineOnFailure = true
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.True(t, xerrors.Is(err, ErrChecksumMismatch))
+
+	assert.NoDirExists(t, path.Join(tmp, "v0.14.1"))
+
+	q, err := v.ListQuarantine()
+	assert.NoError(t, err)
+
+	assert.Len(t, q, 1)
+	assert.Equal(t, "v0.14.1", q[0].Tag)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", q[0].URL)
+	assert.Contains(t, q[0].Reason, "Checksum does not match")
+	assert.FileExists(t, q[0].Path)
+
+	r, err := v.ListInstalledVersions("")
+	assert.NoError(t, err)
+	assert.Len(t, r, 0)
+}
+
+func TestClearQuarantineRemovesAssets(t *testing.T) {
+	_, v, s := setupChecksumFailure(t)
+	v.options.QuarantineOnFailure = true
+
+	v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+
+	err := v.ClearQuarantine()
+	assert.NoError(t, err)
+
+	q, err := v.ListQuarantine()
+	assert.NoError(t, err)
+	assert.Len(t, q, 0)
+}
+
+func TestDownloadReleasePassesWithValidChecksum(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.ChecksumFunc = func(ver, goos, goarch string) string {
+		// sha256 of the content "fake-service-linux"
+		return "59f2d4f597005c9030cd4a90e9e622356561e3751d473f247ec9b151e1ab5393"
+	}
+
+	_, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,277 @@
+package gvm
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+// ErrNoAssets is returned when NoAssetBehavior is NoAssetError and a release has no assets
+var ErrNoAssets = xerrors.New("Release has no assets")
+
+// NoAssetBehavior defines how releases without any assets are handled
+type NoAssetBehavior int
+
+const (
+	// NoAssetSkip ignores releases which have no assets
+	NoAssetSkip NoAssetBehavior = iota
+	// NoAssetError returns ErrNoAssets when a release matching the constraint has no assets
+	NoAssetError
+	// NoAssetSource returns the URL of the source archive for releases which have no assets
+	NoAssetSource
+)
+
+// Release is a GitHub release with the asset for the current platform
+type Release struct {
+	ID  int64
+	Tag string
+	// URL is the download URL of the asset for the current platform, empty
+	// when the release does not have an asset for the platform
+	URL string
+	// AssetID is the ID of the asset for the current platform
+	AssetID     int64
+	PublishedAt time.Time
+	Prerelease  bool
+}
+
+// ListReleasesSince returns the releases which were published after the release with the
+// given tag, newest first. GitHub lists releases newest first so pagination stops as soon
+// as the tag is found, when the tag is not found all releases are returned.
+// Only releases with an asset for the current platform are returned. When UseTags is set, or
+// FallbackToTags is set and there are no releases, the git tags with a higher semantic version
+// than tag are returned with the URL of the source archive
+func (v *VersionsImpl) ListReleasesSince(tag string) ([]Release, error) {
+	if v.options.Frozen {
+		return nil, ErrFrozen
+	}
+
+	if v.options.UseTags {
+		return v.tagReleasesSince(tag)
+	}
+
+	releases := []Release{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		var gr []*github.RepositoryRelease
+		var resp *github.Response
+
+		err := v.retry(context.Background(), func() (*github.Response, error) {
+			var err error
+
+			gr, resp, err = v.client.Repositories.ListReleases(context.Background(), v.options.Organization, v.options.Repo, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to list Github releases: %w", err)
+		}
+
+		if opts.Page == 0 && len(gr) == 0 && v.options.FallbackToTags {
+			return v.tagReleasesSince(tag)
+		}
+
+		for _, r := range gr {
+			if r.TagName == nil {
+				continue
+			}
+
+			if *r.TagName == tag {
+				return releases, nil
+			}
+
+			if a := v.findAsset(*r.TagName, r.Assets); a != nil {
+				releases = append(releases, newRelease(r, a))
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return releases, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// tagReleasesSince returns the git tags with a higher semantic version than tag newest first,
+// when tag is not a semantic version all tags are returned
+func (v *VersionsImpl) tagReleasesSince(tag string) ([]Release, error) {
+	tags, err := v.listTagReleases(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	since, _ := v.parseVersion(tag)
+
+	releases := []Release{}
+	for _, t := range v.SortMapKeys(tags, true) {
+		if since != nil {
+			if tv, _ := v.parseVersion(t); !tv.GreaterThan(since) {
+				break
+			}
+		}
+
+		releases = append(releases, Release{Tag: t, URL: tags[t]})
+	}
+
+	return releases, nil
+}
+
+// GetLatestStable returns the asset for the latest release matching the constraint which was
+// published at least minAge ago, avoiding releases which have only just been published.
+// The releases are listed in the same way as ListReleases, when UseTags is set the time of
+// the commit referenced by the tag is used as the publish time.
+// Returns an empty tag when no release matching the constraint is old enough
+func (v *VersionsImpl) GetLatestStable(constraint string, minAge time.Duration) (string, string, error) {
+	if v.options.Frozen {
+		return "", "", ErrFrozen
+	}
+
+	assets, err := v.cachedReleases(v.constraint(constraint))
+	if err != nil {
+		return "", "", err
+	}
+
+	cutoff := time.Now().Add(-minAge)
+
+	// releases are checked newest first so that only the publish times of the releases
+	// newer than the selected release are fetched
+	for _, tag := range v.SortMapKeys(assets, true) {
+		published, err := v.publishedAt(tag)
+		if err != nil {
+			return "", "", err
+		}
+
+		if published.IsZero() || published.After(cutoff) {
+			continue
+		}
+
+		return tag, assets[tag], nil
+	}
+
+	return "", "", nil
+}
+
+// publishedAt returns the time the release with the given tag was published, when UseTags is
+// set, or FallbackToTags is set and there is no release, the time of the commit referenced by
+// the tag is returned
+func (v *VersionsImpl) publishedAt(tag string) (time.Time, error) {
+	if !v.options.UseTags {
+		r, _, err := v.client.Repositories.GetReleaseByTag(context.Background(), v.options.Organization, v.options.Repo, tag)
+		if err == nil {
+			return r.GetPublishedAt().Time, nil
+		}
+
+		if !v.options.FallbackToTags {
+			return time.Time{}, xerrors.Errorf("Unable to get Github release %s: %w", tag, err)
+		}
+	}
+
+	c, _, err := v.client.Repositories.GetCommit(context.Background(), v.options.Organization, v.options.Repo, tag)
+	if err != nil {
+		return time.Time{}, xerrors.Errorf("Unable to get commit for tag %s: %w", tag, err)
+	}
+
+	return c.GetCommit().GetCommitter().GetDate(), nil
+}
+
+// GetRawRelease returns the GitHub release for the given tag, allowing any field of
+// the release to be read without it being exposed by Versions
+func (v *VersionsImpl) GetRawRelease(tag string) (*github.RepositoryRelease, error) {
+	if v.options.Frozen {
+		return nil, ErrFrozen
+	}
+
+	r, _, err := v.client.Repositories.GetReleaseByTag(context.Background(), v.options.Organization, v.options.Repo, tag)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to get Github release %s: %w", tag, err)
+	}
+
+	return r, nil
+}
+
+// GetReleaseURLChecked returns the URL of the asset for the release with the given tag
+// only when the tag satisfies the constraint, guarding against installing a pinned version
+// outside of the intended range. ErrVersionOutOfRange is returned when the tag does not
+// satisfy the constraint, the constraint is checked before any request is made to GitHub
+func (v *VersionsImpl) GetReleaseURLChecked(tag, constraint string) (string, error) {
+	ok, err := v.InRange(tag, constraint)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", xerrors.Errorf("%w: %s does not satisfy %s", ErrVersionOutOfRange, tag, constraint)
+	}
+
+	r, err := v.GetRawRelease(tag)
+	if err != nil {
+		return "", err
+	}
+
+	a := v.findAsset(tag, r.Assets)
+	if a == nil {
+		return "", xerrors.Errorf("Unable to find asset for release %s", tag)
+	}
+
+	return a.GetBrowserDownloadURL(), nil
+}
+
+// GetReleaseByID returns the release with the given GitHub release ID
+func (v *VersionsImpl) GetReleaseByID(id int64) (Release, error) {
+	r, err := v.getReleaseByID(id)
+	if err != nil {
+		return Release{}, err
+	}
+
+	return newRelease(r, v.findAsset(r.GetTagName(), r.Assets)), nil
+}
+
+// DownloadAssetByID downloads and uncompresses the asset with the given ID from
+// the release with the given ID, returning the path of the executable
+func (v *VersionsImpl) DownloadAssetByID(releaseID, assetID int64) (string, error) {
+	r, err := v.getReleaseByID(releaseID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, a := range r.Assets {
+		if a.GetID() == assetID {
+			return v.DownloadRelease(r.GetTagName(), a.GetBrowserDownloadURL())
+		}
+	}
+
+	return "", xerrors.Errorf("Unable to find asset %d in release %d", assetID, releaseID)
+}
+
+func (v *VersionsImpl) getReleaseByID(id int64) (*github.RepositoryRelease, error) {
+	if v.options.Frozen {
+		return nil, ErrFrozen
+	}
+
+	r, _, err := v.client.Repositories.GetRelease(context.Background(), v.options.Organization, v.options.Repo, id)
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to get Github release %d: %w", id, err)
+	}
+
+	return r, nil
+}
+
+// newRelease creates a Release from the GitHub release and the selected asset
+// which is nil when the release does not have an asset for the platform
+func newRelease(r *github.RepositoryRelease, a *github.ReleaseAsset) Release {
+	rel := Release{
+		ID:         r.GetID(),
+		Tag:        r.GetTagName(),
+		URL:        a.GetBrowserDownloadURL(),
+		AssetID:    a.GetID(),
+		Prerelease: r.GetPrerelease(),
+	}
+
+	if r.PublishedAt != nil {
+		rel.PublishedAt = r.PublishedAt.Time
+	}
+
+	return rel
+}
@@ -0,0 +1,360 @@
+package gvm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestListReleasesSinceReturnsNewerReleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	// GitHub lists releases newest first
+	s.addRelease("v0.14.3", "fake-service-linux")
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.0", "fake-service-linux")
+
+	r, err := v.ListReleasesSince("v0.14.1")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 2)
+	assert.Equal(t, "v0.14.3", r[0].Tag)
+	assert.Equal(t, s.URL+"/download/v0.14.3/fake-service-linux", r[0].URL)
+	assert.Equal(t, "v0.14.2", r[1].Tag)
+}
+
+func TestListReleasesSinceStopsPaginatingAtTag(t *testing.T) {
+	_, v, s := setupStub(t)
+	for i := 250; i > 0; i-- {
+		s.addRelease(fmt.Sprintf("v0.%d.0", i), "fake-service-linux")
+	}
+
+	r, err := v.ListReleasesSince("v0.200.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 50)
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestListReleasesSinceReturnsAllReleasesWhenTagNotFound(t *testing.T) {
+	_, v, s := setupStub(t)
+	for i := 150; i > 0; i-- {
+		s.addRelease(fmt.Sprintf("v0.%d.0", i), "fake-service-linux")
+	}
+
+	r, err := v.ListReleasesSince("v1.0.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 150)
+	assert.Equal(t, 2, s.releasePages)
+}
+
+func publishedAgo(r *github.RepositoryRelease, d time.Duration) {
+	r.PublishedAt = &github.Timestamp{Time: time.Now().Add(-d)}
+}
+
+func TestGetLatestStableReturnsLatestReleaseOlderThanMinAge(t *testing.T) {
+	_, v, s := setupStub(t)
+	publishedAgo(s.addRelease("v0.14.3", "fake-service-linux"), time.Hour)
+	publishedAgo(s.addRelease("v0.14.2", "fake-service-linux"), 3*24*time.Hour)
+	publishedAgo(s.addRelease("v0.14.1", "fake-service-linux"), 10*24*time.Hour)
+	publishedAgo(s.addRelease("v0.14.0", "fake-service-linux"), 30*24*time.Hour)
+
+	tag, url, err := v.GetLatestStable("~0.14.0", 7*24*time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", url)
+}
+
+func TestGetLatestStableFiltersByConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	publishedAgo(s.addRelease("v1.0.0", "fake-service-linux"), 30*24*time.Hour)
+	publishedAgo(s.addRelease("v0.14.1", "fake-service-linux"), 30*24*time.Hour)
+
+	tag, _, err := v.GetLatestStable("~0.14.0", 7*24*time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+}
+
+func TestGetLatestStableReturnsEmptyWhenNoReleaseOldEnough(t *testing.T) {
+	_, v, s := setupStub(t)
+	publishedAgo(s.addRelease("v0.14.1", "fake-service-linux"), time.Hour)
+	s.addRelease("v0.14.0", "fake-service-linux")
+
+	tag, url, err := v.GetLatestStable("", 7*24*time.Hour)
+	assert.NoError(t, err)
+
+	assert.Empty(t, tag)
+	assert.Empty(t, url)
+}
+
+func TestGetLatestStableUsesReleaseCache(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.CacheTTL = time.Minute
+	publishedAgo(s.addRelease("v0.14.2", "fake-service-linux"), time.Hour)
+	publishedAgo(s.addRelease("v0.14.1", "fake-service-linux"), 10*24*time.Hour)
+
+	_, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	tag, _, err := v.GetLatestStable("", 7*24*time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestGetLatestStableUsesTags(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.UseTags = true
+	s.tags = append(s.tags,
+		&github.RepositoryTag{Name: github.String("v0.14.2")},
+		&github.RepositoryTag{Name: github.String("v0.14.1")},
+	)
+	s.commitDates["v0.14.2"] = time.Now().Add(-time.Hour)
+	s.commitDates["v0.14.1"] = time.Now().Add(-10 * 24 * time.Hour)
+
+	tag, url, err := v.GetLatestStable("", 7*24*time.Hour)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", tag)
+	assert.Equal(t, "https://github.com/nicholasjackson/fake-service/archive/v0.14.1.tar.gz", url)
+	assert.Equal(t, 0, s.releasePages)
+}
+
+func TestGetLatestStableReturnsErrorWhenFrozen(t *testing.T) {
+	_, v, _ := setupStub(t)
+	v.options.Frozen = true
+
+	_, _, err := v.GetLatestStable("", time.Hour)
+	assert.True(t, xerrors.Is(err, ErrFrozen))
+}
+
+func TestGetRawReleaseReturnsGitHubRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	rel := s.addRelease("v0.14.1", "fake-service-linux")
+	rel.Name = github.String("Fake Service 0.14.1")
+
+	r, err := v.GetRawRelease("v0.14.1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Fake Service 0.14.1", r.GetName())
+	assert.Len(t, r.Assets, 1)
+}
+
+func TestGetRawReleaseReturnsErrorWhenNotFound(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.GetRawRelease("v0.14.1")
+	assert.Error(t, err)
+}
+
+func TestGetReleaseURLCheckedReturnsURLWhenTagSatisfiesConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	url, err := v.GetReleaseURLChecked("v0.14.1", "~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", url)
+}
+
+func TestGetReleaseURLCheckedReturnsErrorWhenTagOutOfRange(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.14.1", "fake-service-linux")
+
+	_, err := v.GetReleaseURLChecked("v1.14.1", "~0.14.0")
+	assert.True(t, xerrors.Is(err, ErrVersionOutOfRange))
+}
+
+func TestGetReleaseURLCheckedReturnsErrorWhenInvalidConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.GetReleaseURLChecked("v0.14.1", "bad")
+	assert.Error(t, err)
+}
+
+func TestGetReleaseByIDReturnsRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.0", "fake-service-linux")
+	rel := s.addRelease("v0.14.1", "fake-service-linux")
+
+	r, err := v.GetReleaseByID(rel.GetID())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "v0.14.1", r.Tag)
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", r.URL)
+	assert.Equal(t, rel.Assets[0].GetID(), r.AssetID)
+}
+
+func TestGetReleaseByIDReturnsErrorWhenNotFound(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.GetReleaseByID(12)
+	assert.Error(t, err)
+}
+
+func TestGetReleaseByIDReturnsErrorWhenFrozen(t *testing.T) {
+	_, v, s := setupStub(t)
+	rel := s.addRelease("v0.14.1", "fake-service-linux")
+	v.options.Frozen = true
+
+	_, err := v.GetReleaseByID(rel.GetID())
+	assert.True(t, xerrors.Is(err, ErrFrozen))
+}
+
+func TestDownloadAssetByIDDownloadsAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	rel := s.addRelease("v0.14.1", "fake-service-linux.tar.gz")
+	s.assets["/download/v0.14.1/fake-service-linux.tar.gz"] = tarGz(t, map[string]string{"fake-service-linux": "binary"})
+
+	fp, err := v.DownloadAssetByID(rel.GetID(), rel.Assets[0].GetID())
+	assert.NoError(t, err)
+
+	assert.Contains(t, fp, "v0.14.1")
+	assert.FileExists(t, fp)
+}
+
+func TestDownloadAssetByIDReturnsErrorWhenAssetNotInRelease(t *testing.T) {
+	_, v, s := setupStub(t)
+	rel := s.addRelease("v0.14.1", "fake-service-linux")
+	other := s.addRelease("v0.14.2", "fake-service-linux")
+
+	_, err := v.DownloadAssetByID(rel.GetID(), other.Assets[0].GetID())
+	assert.Error(t, err)
+}
+
+func TestListReleasesSkipsReleasesWithoutAssets(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.2")
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Contains(t, r, "v0.14.1")
+}
+
+func TestListReleasesReturnsErrorForReleasesWithoutAssets(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.NoAssetBehavior = NoAssetError
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.2")
+
+	_, err := v.ListReleases("")
+	assert.True(t, xerrors.Is(err, ErrNoAssets))
+
+	// releases outside the constraint are ignored
+	_, err = v.ListReleases("< 0.14.2")
+	assert.NoError(t, err)
+}
+
+func TestListReleasesReturnsSourceForReleasesWithoutAssets(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.NoAssetBehavior = NoAssetSource
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.14.2")
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service-linux", r["v0.14.1"])
+	assert.Equal(t, "https://github.com/nicholasjackson/fake-service/archive/v0.14.2.tar.gz", r["v0.14.2"])
+}
+
+func TestListReleasesExcludesReleasesMissingAuxAssets(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.RequiredAuxAssetsFunc = func(ver string) []string {
+		return []string{"checksums.txt", "checksums.txt.sig"}
+	}
+	s.addRelease("v0.14.1", "fake-service-linux", "checksums.txt", "checksums.txt.sig")
+	s.addRelease("v0.14.2", "fake-service-linux", "checksums.txt")
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Contains(t, r, "v0.14.1")
+}
+
+func TestListReleasesFetchesAllPages(t *testing.T) {
+	_, v, s := setupStub(t)
+	for i := 250; i > 0; i-- {
+		s.addRelease(fmt.Sprintf("v0.%d.0", i), "fake-service-linux")
+	}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 250)
+	assert.Equal(t, 3, s.releasePages)
+}
+
+func TestListReleasesStopsAtMaxReleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.MaxReleases = 150
+	for i := 250; i > 0; i-- {
+		s.addRelease(fmt.Sprintf("v0.%d.0", i), "fake-service-linux")
+	}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 150)
+	assert.Contains(t, r, "v0.250.0")
+	assert.NotContains(t, r, "v0.100.0")
+	assert.Equal(t, 2, s.releasePages)
+}
+
+func TestListReleasesRequestsSmallPageForMaxReleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.MaxReleases = 5
+	for i := 50; i > 0; i-- {
+		s.addRelease(fmt.Sprintf("v0.%d.0", i), "fake-service-linux")
+	}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 5)
+	assert.Contains(t, r, "v0.46.0")
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestListReleasesSinceReturnsErrorWhenFrozen(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.Frozen = true
+	s.addRelease("v0.14.1", "fake-service-linux")
+
+	_, err := v.ListReleasesSince("v0.14.0")
+	assert.Equal(t, ErrFrozen, err)
+	assert.Equal(t, 0, s.releasePages)
+}
+
+func TestListReleasesSinceUsesTags(t *testing.T) {
+	for _, o := range []string{"UseTags", "FallbackToTags"} {
+		_, v, s := setupStub(t)
+		v.options.UseTags = o == "UseTags"
+		v.options.FallbackToTags = o == "FallbackToTags"
+		addTag(s, "v0.14.1", "aaa")
+		addTag(s, "v0.14.3", "bbb")
+		addTag(s, "v0.14.2", "ccc")
+		addTag(s, "v0.13.0", "ddd")
+
+		r, err := v.ListReleasesSince("v0.14.1")
+		assert.NoError(t, err, o)
+
+		assert.Len(t, r, 2, o)
+		assert.Equal(t, "v0.14.3", r[0].Tag, o)
+		assert.Equal(t, "https://github.com/nicholasjackson/fake-service/archive/v0.14.3.tar.gz", r[0].URL, o)
+		assert.Equal(t, "v0.14.2", r[1].Tag, o)
+	}
+}
@@ -0,0 +1,140 @@
+package gvm
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+// defaultListRetries is the number of retries when ListRetries is not set
+const defaultListRetries = 3
+
+// defaultRetryBackoff is the delay before the first retry when RetryBackoff is not set
+const defaultRetryBackoff = 1 * time.Second
+
+// defaultGitHubTimeout is the maximum time for a GitHub request when GitHubTimeout is not set
+const defaultGitHubTimeout = 30 * time.Second
+
+// ErrSecondaryRateLimited is returned when GitHub rejects a request due to its secondary
+// rate limit and the request is not retried
+var ErrSecondaryRateLimited = xerrors.New("GitHub secondary rate limit exceeded")
+
+// sleep pauses for d or until ctx is done, returning the context error when ctx is done
+// first. It is a variable so that tests can replace it
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retry calls the GitHub request f, retrying up to ListRetries times using the configured
+// Backoff when the request fails with a 5xx status or the connection is reset. Waiting
+// between retries stops when ctx is done and the context error is returned
+func (v *VersionsImpl) retry(ctx context.Context, f func() (*github.Response, error)) error {
+	backoff := v.backoff()
+
+	for attempt := 0; ; attempt++ {
+		resp, err := f()
+		if err == nil {
+			return nil
+		}
+
+		if retryAfter, ok := secondaryRateLimit(resp, err); ok {
+			if attempt >= v.options.ListRetries {
+				return xerrors.Errorf("%w: %s", ErrSecondaryRateLimited, err)
+			}
+
+			// wait for the time requested by GitHub, retrying sooner extends the block
+			if retryAfter == 0 {
+				retryAfter = backoff.NextDelay(attempt)
+			}
+
+			if serr := sleep(ctx, retryAfter); serr != nil {
+				return serr
+			}
+
+			continue
+		}
+
+		if attempt >= v.options.ListRetries || !retryable(resp, err) {
+			return err
+		}
+
+		if serr := sleep(ctx, backoff.NextDelay(attempt)); serr != nil {
+			return serr
+		}
+	}
+}
+
+// secondaryRateLimit returns true and the delay from the Retry-After header when the request
+// was rejected by GitHub's secondary rate limit. Secondary limits return a 403 or 429 status
+// with a Retry-After header, unlike the primary rate limit they are not reported by the
+// X-RateLimit-Remaining header
+func secondaryRateLimit(resp *github.Response, err error) (time.Duration, bool) {
+	var abuse *github.AbuseRateLimitError
+	if xerrors.As(err, &abuse) {
+		return abuse.GetRetryAfter(), true
+	}
+
+	if resp == nil || resp.Response == nil {
+		return 0, false
+	}
+
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	h := resp.Header.Get("Retry-After")
+	if h == "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return 0, false
+	}
+
+	seconds, perr := strconv.Atoi(h)
+	if perr != nil || seconds < 0 {
+		return 0, true
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// jitter returns the backoff adjusted by a random amount up to the given fraction
+// of the backoff, the returned delay is never negative and never overflows
+func jitter(backoff time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return backoff
+	}
+
+	d := float64(backoff) + float64(backoff)*fraction*(2*rand.Float64()-1)
+	if d < 0 {
+		return 0
+	}
+
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return time.Duration(d)
+}
+
+// retryable returns true when the request failed with a 5xx status or the connection was reset,
+// 4xx statuses are not retried
+func retryable(resp *github.Response, err error) bool {
+	if resp != nil && resp.Response != nil {
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+
+	return xerrors.Is(err, syscall.ECONNRESET)
+}
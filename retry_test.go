@@ -0,0 +1,200 @@
+package gvm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestListReleasesRetriesServerErrors(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.RetryBackoff = time.Millisecond
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.releaseErrors = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Contains(t, r, "v0.14.1")
+	assert.Equal(t, 3, s.releasePages)
+}
+
+func TestListReleasesReturnsErrorWhenRetriesExhausted(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.RetryBackoff = time.Millisecond
+	v.options.ListRetries = 1
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.releaseErrors = []int{http.StatusBadGateway, http.StatusBadGateway}
+
+	_, err := v.ListReleases("")
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, s.releasePages)
+}
+
+func TestListReleasesDoesNotRetryClientErrors(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.RetryBackoff = time.Millisecond
+	s.releaseErrors = []int{http.StatusNotFound}
+
+	_, err := v.ListReleases("")
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestListReleasesDoesNotRetryWhenDisabled(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.ListRetries = -1
+	s.releaseErrors = []int{http.StatusServiceUnavailable}
+
+	_, err := v.ListReleases("")
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestJitterReturnsDelayWithinRange(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(10*time.Second, 0.2)
+
+		assert.GreaterOrEqual(t, int64(d), int64(8*time.Second))
+		assert.LessOrEqual(t, int64(d), int64(12*time.Second))
+	}
+}
+
+func TestJitterReturnsBackoffWhenDisabled(t *testing.T) {
+	assert.Equal(t, 10*time.Second, jitter(10*time.Second, 0))
+	assert.Equal(t, 10*time.Second, jitter(10*time.Second, -0.5))
+}
+
+func TestJitterNeverReturnsNegativeDelay(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second, 5)
+
+		assert.GreaterOrEqual(t, int64(d), int64(0))
+	}
+}
+
+func mockSleep(t *testing.T) *[]time.Duration {
+	original := sleep
+	t.Cleanup(func() { sleep = original })
+
+	delays := []time.Duration{}
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	return &delays
+}
+
+func TestListReleasesHonoursRetryAfterForSecondaryRateLimit(t *testing.T) {
+	_, v, s := setupStub(t)
+	delays := mockSleep(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.releaseErrors = []int{http.StatusForbidden}
+	s.retryAfter = "7"
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Contains(t, r, "v0.14.1")
+	assert.Equal(t, []time.Duration{7 * time.Second}, *delays)
+}
+
+func TestListReleasesReturnsErrSecondaryRateLimitedWhenRetriesDisabled(t *testing.T) {
+	_, v, s := setupStub(t)
+	mockSleep(t)
+	v.options.ListRetries = -1
+	s.releaseErrors = []int{http.StatusForbidden}
+	s.retryAfter = "7"
+
+	_, err := v.ListReleases("")
+	assert.True(t, xerrors.Is(err, ErrSecondaryRateLimited))
+
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestListReleasesDoesNotRetryForbiddenWithoutRetryAfter(t *testing.T) {
+	_, v, s := setupStub(t)
+	delays := mockSleep(t)
+	s.releaseErrors = []int{http.StatusForbidden}
+
+	_, err := v.ListReleases("")
+	assert.Error(t, err)
+	assert.False(t, xerrors.Is(err, ErrSecondaryRateLimited))
+
+	assert.Len(t, *delays, 0)
+}
+
+func TestSecondaryRateLimitDetectsAbuseRateLimitError(t *testing.T) {
+	d := 30 * time.Second
+	err := &github.AbuseRateLimitError{Message: "abuse", RetryAfter: &d}
+
+	retryAfter, ok := secondaryRateLimit(nil, err)
+
+	assert.True(t, ok)
+	assert.Equal(t, d, retryAfter)
+}
+
+func TestWarmStopsRetryingWhenContextCancelled(t *testing.T) {
+	_, v, s := setupStub(t)
+	v.options.RetryBackoff = time.Hour
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.releaseErrors = []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := v.Warm(ctx)
+
+	assert.True(t, xerrors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	assert.Equal(t, 1, s.releasePages)
+}
+
+func TestListReleasesWaitsForRetryAfterUnlessCancelled(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.releaseErrors = []int{http.StatusForbidden}
+	s.retryAfter = "3600"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := v.listReleases(ctx, "")
+
+	assert.True(t, xerrors.Is(err, context.Canceled))
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
+
+func TestListReleasesReturnsErrorWhenGitHubDoesNotRespond(t *testing.T) {
+	_, v := setup(t)
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(s.Close)
+
+	o := v.options
+	o.GitHubTimeout = 50 * time.Millisecond
+	o.ListRetries = -1
+	v = New(o).(*VersionsImpl)
+	v.client.BaseURL, _ = url.Parse(s.URL + "/")
+
+	start := time.Now()
+	_, err := v.ListReleases("")
+
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+}
@@ -0,0 +1,73 @@
+package gvm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AssetNameFunc returns the name of the release asset for the given version, GOOS and GOARCH
+type AssetNameFunc func(ver, goos, goarch string) string
+
+// schemes are the registered naming schemes selectable with Options.Scheme
+var schemes = map[string]AssetNameFunc{
+	"kind":     rawBinaryScheme("kind", ""),
+	"k3d":      rawBinaryScheme("k3d", ".exe"),
+	"minikube": rawBinaryScheme("minikube", ".exe"),
+	"k9s":      k9sAssetName,
+}
+
+// schemesMutex guards schemes which can be registered while Versions are in use
+var schemesMutex sync.RWMutex
+
+// RegisterScheme registers the asset naming function for the named scheme, selected by setting
+// Options.Scheme. Registering a name which already exists replaces the existing scheme
+func RegisterScheme(name string, fn AssetNameFunc) {
+	schemesMutex.Lock()
+	defer schemesMutex.Unlock()
+
+	schemes[name] = fn
+}
+
+// schemeAssetName returns an AssetNameFunc which calls the function registered for the scheme,
+// the scheme is looked up for each call so schemes can be registered after New is called.
+// An unknown scheme returns an empty name which does not match any asset
+func schemeAssetName(name string) AssetNameFunc {
+	return func(ver, goos, goarch string) string {
+		schemesMutex.RLock()
+		fn, ok := schemes[name]
+		schemesMutex.RUnlock()
+
+		if !ok {
+			return ""
+		}
+
+		return fn(ver, goos, goarch)
+	}
+}
+
+// rawBinaryScheme returns an AssetNameFunc for projects which release uncompressed binaries
+// named <name>-<goos>-<goarch> e.g. kind-linux-amd64, ext is appended for windows
+func rawBinaryScheme(name, ext string) AssetNameFunc {
+	return func(ver, goos, goarch string) string {
+		if goos == "windows" {
+			return fmt.Sprintf("%s-%s-%s%s", name, goos, goarch, ext)
+		}
+
+		return fmt.Sprintf("%s-%s-%s", name, goos, goarch)
+	}
+}
+
+// k9sAssetName returns the name of the k9s archive e.g. k9s_Linux_amd64.tar.gz
+func k9sAssetName(ver, goos, goarch string) string {
+	// GOOS values are lower case ASCII so only the first byte needs to be capitalised
+	if goos != "" {
+		goos = strings.ToUpper(goos[:1]) + goos[1:]
+	}
+
+	if goos == "Windows" {
+		return fmt.Sprintf("k9s_%s_%s.zip", goos, goarch)
+	}
+
+	return fmt.Sprintf("k9s_%s_%s.tar.gz", goos, goarch)
+}
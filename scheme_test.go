@@ -0,0 +1,54 @@
+package gvm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemeSelectsRegisteredAssetName(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service_0.14.1_linux_x64.tar.gz")
+
+	RegisterScheme("fake-service", func(ver, goos, goarch string) string {
+		return fmt.Sprintf("fake-service_%s_%s_%s.tar.gz", ver, goos, goarch)
+	})
+	v.options.AssetNameFunc = schemeAssetName("fake-service")
+
+	r, err := v.ListReleases("")
+	assert.NoError(t, err)
+
+	assert.Equal(t, s.URL+"/download/v0.14.1/fake-service_0.14.1_linux_x64.tar.gz", r["v0.14.1"])
+}
+
+func TestNewUsesSchemeInPlaceOfAssetNameFunc(t *testing.T) {
+	v := New(Options{
+		Scheme:        "kind",
+		AssetNameFunc: func(ver, goos, goarch string) string { return "other" },
+	}).(*VersionsImpl)
+
+	assert.Equal(t, "kind-linux-amd64", v.options.AssetNameFunc("0.20.0", "linux", "amd64"))
+}
+
+func TestUnknownSchemeMatchesNoAssets(t *testing.T) {
+	fn := schemeAssetName("unknown")
+
+	assert.Equal(t, "", fn("0.14.1", "linux", "amd64"))
+}
+
+func TestBuiltInSchemes(t *testing.T) {
+	tt := []struct {
+		scheme, goos, goarch, name string
+	}{
+		{"kind", "windows", "amd64", "kind-windows-amd64"},
+		{"k3d", "darwin", "arm64", "k3d-darwin-arm64"},
+		{"minikube", "windows", "amd64", "minikube-windows-amd64.exe"},
+		{"k9s", "linux", "amd64", "k9s_Linux_amd64.tar.gz"},
+		{"k9s", "windows", "amd64", "k9s_Windows_amd64.zip"},
+	}
+
+	for _, tc := range tt {
+		assert.Equal(t, tc.name, schemeAssetName(tc.scheme)("1.0.0", tc.goos, tc.goarch), tc.scheme)
+	}
+}
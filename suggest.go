@@ -0,0 +1,62 @@
+package gvm
+
+import (
+	"regexp"
+
+	"github.com/Masterminds/semver"
+	"golang.org/x/xerrors"
+)
+
+// constraintVersion matches the first version in a constraint e.g. 2.0 in "~2.0"
+var constraintVersion = regexp.MustCompile(`\d+(\.\d+)?(\.\d+)?`)
+
+// SuggestVersions returns the closest available releases to a constraint which has no
+// matching releases, the nearest release below and the nearest release above the version
+// in the constraint. e.g. "~2.0" with the releases 1.9.3 and 3.0.0 returns both.
+// An empty list is returned when releases match the constraint
+func (v *VersionsImpl) SuggestVersions(constraint string) ([]string, error) {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return nil, xerrors.Errorf("Invalid sematic version constraint: %w", err)
+	}
+
+	pivot, err := semver.NewVersion(constraintVersion.FindString(constraint))
+	if err != nil {
+		return nil, xerrors.Errorf("Unable to find version in constraint %s: %w", constraint, err)
+	}
+
+	assets, err := v.cachedReleases("")
+	if err != nil {
+		return nil, err
+	}
+
+	var below, above string
+	for _, tag := range v.SortMapKeys(assets, false) {
+		tv, err := v.parseVersion(tag)
+		if err != nil {
+			continue
+		}
+
+		if c.Check(tv) {
+			return []string{}, nil
+		}
+
+		if tv.LessThan(pivot) {
+			below = tag
+			continue
+		}
+
+		if above == "" {
+			above = tag
+		}
+	}
+
+	suggestions := []string{}
+	for _, s := range []string{below, above} {
+		if s != "" {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	return suggestions, nil
+}
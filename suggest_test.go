@@ -0,0 +1,47 @@
+package gvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestVersionsReturnsNearestReleases(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v3.1.0", "fake-service-linux")
+	s.addRelease("v3.0.0", "fake-service-linux")
+	s.addRelease("v1.9.3", "fake-service-linux")
+	s.addRelease("v1.9.2", "fake-service-linux")
+
+	sv, err := v.SuggestVersions("~2.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.9.3", "v3.0.0"}, sv)
+}
+
+func TestSuggestVersionsReturnsOnlyBelowWhenNothingAbove(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.9.3", "fake-service-linux")
+
+	sv, err := v.SuggestVersions(">= 2.0.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"v1.9.3"}, sv)
+}
+
+func TestSuggestVersionsReturnsEmptyWhenConstraintMatches(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.9.3", "fake-service-linux")
+
+	sv, err := v.SuggestVersions("~1.9")
+	assert.NoError(t, err)
+
+	assert.Empty(t, sv)
+}
+
+func TestSuggestVersionsReturnsErrorForInvalidConstraint(t *testing.T) {
+	_, v, _ := setupStub(t)
+
+	_, err := v.SuggestVersions("abc")
+	assert.Error(t, err)
+}
@@ -0,0 +1,212 @@
+package gvm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-github/github"
+	"golang.org/x/xerrors"
+)
+
+// ErrUnknownTag is returned when a git tag does not exist in the repository
+var ErrUnknownTag = xerrors.New("Unknown git tag")
+
+// tagPrefix is the prefix used by release tags e.g. v1.2.3, the default for TagPrefix
+const tagPrefix = "v"
+
+// NormalizeTag returns the version for a release tag by removing the v prefix
+// e.g. v1.2.3 returns 1.2.3, versions without the prefix are returned unchanged.
+// Use VersionsImpl.NormalizeTag for the prefix of the configured repository
+func NormalizeTag(tag string) string {
+	return strings.TrimPrefix(tag, tagPrefix)
+}
+
+// DenormalizeTag returns the release tag for a version by adding the v prefix
+// e.g. 1.2.3 returns v1.2.3, tags which already have the prefix are returned unchanged.
+// Use VersionsImpl.DenormalizeTag for the prefix of the configured repository
+func DenormalizeTag(version string) string {
+	return tagPrefix + NormalizeTag(version)
+}
+
+// goTagPrefix is the prefix used by Go toolchain tags e.g. go1.21.3
+const goTagPrefix = "go"
+
+// releasePrefix returns the prefix of the release tags, go when GoToolchainMode is set
+// otherwise TagPrefix
+func (v *VersionsImpl) releasePrefix() string {
+	if v.options.GoToolchainMode {
+		return goTagPrefix
+	}
+
+	if v.options.TagPrefix == "" {
+		return tagPrefix
+	}
+
+	return v.options.TagPrefix
+}
+
+// NormalizeTag returns the version for a release tag by removing the release tag prefix,
+// e.g. v1.2.3 returns 1.2.3, when TagPrefix is release- release-1.2.3 returns 1.2.3 or when
+// GoToolchainMode is set go1.21.3 returns 1.21.3.
+// Tags with a v prefix are always normalized, versions without a prefix are returned unchanged
+func (v *VersionsImpl) NormalizeTag(tag string) string {
+	if p := v.releasePrefix(); strings.HasPrefix(tag, p) {
+		return strings.TrimPrefix(tag, p)
+	}
+
+	return NormalizeTag(tag)
+}
+
+// DenormalizeTag returns the release tag for a version by adding the release tag prefix,
+// e.g. 1.2.3 returns v1.2.3, when TagPrefix is release- release-1.2.3 or when GoToolchainMode
+// is set go1.2.3. Tags which already have the prefix are returned unchanged
+func (v *VersionsImpl) DenormalizeTag(version string) string {
+	return v.releasePrefix() + v.NormalizeTag(version)
+}
+
+// parseVersion parses the semantic version of a release tag, prefixes other than v which
+// are understood by semver are removed
+func (v *VersionsImpl) parseVersion(tag string) (*semver.Version, error) {
+	if v.releasePrefix() != tagPrefix {
+		tag = v.NormalizeTag(tag)
+	}
+
+	return semver.NewVersion(tag)
+}
+
+// goAssetName returns the name of the Go distribution archive e.g. go1.21.3.linux-amd64.tar.gz
+func goAssetName(version, goos, goarch string) string {
+	if goos == "windows" {
+		return fmt.Sprintf("go%s.%s-%s.zip", version, goos, goarch)
+	}
+
+	return fmt.Sprintf("go%s.%s-%s.tar.gz", version, goos, goarch)
+}
+
+// goExeName returns the path of the go command in the Go distribution archive
+func goExeName(version, goos, goarch string) string {
+	if goos == "windows" {
+		return "go/bin/go.exe"
+	}
+
+	return "go/bin/go"
+}
+
+// listTags returns all the git tags for the repository
+func (v *VersionsImpl) listTags() ([]*github.RepositoryTag, error) {
+	return v.listTagsContext(context.Background())
+}
+
+// listTagsContext is listTags using the given context for the requests
+func (v *VersionsImpl) listTagsContext(ctx context.Context) ([]*github.RepositoryTag, error) {
+	tags := []*github.RepositoryTag{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		var gt []*github.RepositoryTag
+		var resp *github.Response
+
+		err := v.retry(ctx, func() (*github.Response, error) {
+			var err error
+
+			gt, resp, err = v.client.Repositories.ListTags(ctx, v.options.Organization, v.options.Repo, opts)
+			return resp, err
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("Unable to list Github tags: %w", err)
+		}
+
+		tags = append(tags, gt...)
+
+		if resp.NextPage == 0 {
+			return tags, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetTaggedRelease resolves a named git tag such as stable or canary, which is not necessarily a
+// semantic version, to the release whose tag references the same commit and returns the release
+// tag and the URL of the asset for the platform. When several release tags reference the commit
+// the highest version with an asset for the platform is returned. Returns ErrUnknownTag when the
+// git tag does not exist
+func (v *VersionsImpl) GetTaggedRelease(gitTag string) (string, string, error) {
+	if v.options.Frozen {
+		return "", "", ErrFrozen
+	}
+
+	gt, err := v.listTags()
+	if err != nil {
+		return "", "", err
+	}
+
+	sha := ""
+	for _, t := range gt {
+		if t.GetName() == gitTag {
+			sha = t.GetCommit().GetSHA()
+		}
+	}
+
+	if sha == "" {
+		return "", "", xerrors.Errorf("%w: %s", ErrUnknownTag, gitTag)
+	}
+
+	candidates := map[string]string{}
+	for _, t := range gt {
+		if t.GetCommit().GetSHA() != sha {
+			continue
+		}
+
+		if _, err := v.parseVersion(t.GetName()); err == nil {
+			candidates[t.GetName()] = t.GetName()
+		}
+	}
+
+	for _, tag := range v.SortMapKeys(candidates, true) {
+		r, err := v.GetRawRelease(tag)
+		if err != nil {
+			continue
+		}
+
+		if a := v.findAsset(tag, r.Assets); a != nil {
+			return tag, a.GetBrowserDownloadURL(), nil
+		}
+	}
+
+	return "", "", xerrors.Errorf("Unable to find a release with an asset for the platform for git tag %s", gitTag)
+}
+
+// listTagReleases returns a map of the git tags which match the constraint with the URL of
+// the source archive for the tag, tags which are not valid semantic versions are ignored
+func (v *VersionsImpl) listTagReleases(ctx context.Context, constraint string) (map[string]string, error) {
+	gt, err := v.listTagsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	for _, t := range gt {
+		if _, err := v.parseVersion(*t.Name); err != nil {
+			continue
+		}
+
+		if constraint != "" {
+			valid, err := v.InRange(*t.Name, constraint)
+			if err != nil || !valid {
+				continue
+			}
+		}
+
+		tags[*t.Name] = v.sourceURL(*t.Name)
+	}
+
+	return tags, nil
+}
+
+// sourceURL returns the URL of the source archive for the given tag
+func (v *VersionsImpl) sourceURL(tag string) string {
+	return fmt.Sprintf("https://github.com/%s/%s/archive/%s.tar.gz", v.options.Organization, v.options.Repo, tag)
+}
@@ -0,0 +1,76 @@
+package gvm
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// throttle limits the rate bytes are read across every download made by a Versions,
+// so that concurrent downloads share the bandwidth set by MaxBytesPerSecond
+type throttle struct {
+	mutex sync.Mutex
+	rate  int64
+	// next is the time when the bytes read so far would have been read at the rate
+	next time.Time
+}
+
+// newThrottle returns a throttle for the given bytes per second, nil when the rate is
+// zero and downloads are not throttled
+func newThrottle(rate int64) *throttle {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &throttle{rate: rate}
+}
+
+// wait blocks until n more bytes can be read without exceeding the rate, returns the
+// error from ctx when it is cancelled while waiting
+func (t *throttle) wait(ctx context.Context, n int) error {
+	t.mutex.Lock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+
+	t.next = t.next.Add(time.Duration(int64(n) * int64(time.Second) / t.rate))
+	d := t.next.Sub(now)
+	t.mutex.Unlock()
+
+	return sleep(ctx, d)
+}
+
+// reader returns r throttled to the rate, r is returned unchanged when t is nil.
+// Reads return the error from ctx when it is cancelled while waiting
+func (t *throttle) reader(ctx context.Context, r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+
+	return &throttledReader{ctx: ctx, r: r, t: t}
+}
+
+// throttledReader is a reader which is limited to the rate of the throttle
+type throttledReader struct {
+	ctx context.Context
+	r   io.Reader
+	t   *throttle
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	// read at most one second of data at a time so that the rate stays smooth
+	if int64(len(p)) > tr.t.rate {
+		p = p[:tr.t.rate]
+	}
+
+	n, err := tr.r.Read(p)
+	if n > 0 {
+		if werr := tr.t.wait(tr.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+
+	return n, err
+}
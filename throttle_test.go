@@ -0,0 +1,56 @@
+package gvm
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestThrottledReaderLimitsRate(t *testing.T) {
+	delays := mockSleep(t)
+	th := newThrottle(1000)
+
+	d, err := ioutil.ReadAll(th.reader(context.Background(), bytes.NewReader(make([]byte, 2500))))
+	assert.NoError(t, err)
+	assert.Len(t, d, 2500)
+
+	// each read waits until the data would have been read at the rate, sleep is mocked
+	// so the clock does not advance and the final wait covers all of the data
+	assert.InDelta(t, 2500*time.Millisecond, (*delays)[len(*delays)-1], float64(50*time.Millisecond))
+}
+
+func TestThrottledReaderReturnsErrorWhenContextCancelled(t *testing.T) {
+	th := newThrottle(1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ioutil.ReadAll(th.reader(ctx, bytes.NewReader(make([]byte, 2500))))
+	assert.True(t, xerrors.Is(err, context.Canceled))
+}
+
+func TestNewThrottleReturnsNilWhenUnlimited(t *testing.T) {
+	assert.Nil(t, newThrottle(0))
+
+	r := bytes.NewReader(nil)
+	assert.Equal(t, r, newThrottle(0).reader(context.Background(), r))
+}
+
+func TestDownloadIsThrottled(t *testing.T) {
+	_, v, s := setupStub(t)
+	delays := mockSleep(t)
+	v.throttle = newThrottle(1 << 10)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.assets["/download/v0.14.1/fake-service-linux"] = make([]byte, 4<<10)
+
+	fp, err := v.DownloadRelease("v0.14.1", s.URL+"/download/v0.14.1/fake-service-linux")
+	assert.NoError(t, err)
+	assert.FileExists(t, fp)
+
+	// the final wait covers all of the data read at 1KB per second
+	assert.InDelta(t, 4*time.Second, (*delays)[len(*delays)-1], float64(100*time.Millisecond))
+}
@@ -0,0 +1,90 @@
+package gvm
+
+import (
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// checksumFileNames are the names of release assets which contain the checksums of every
+// asset in the release
+var checksumFileNames = []string{"checksums.txt", "sha256sums", "sha256sums.txt", "sha512sums", "sha512sums.txt"}
+
+// checksumFileSuffixes are the suffixes of checksums files named after the project and
+// version, e.g. fake-service_0.14.1_checksums.txt created by goreleaser
+var checksumFileSuffixes = []string{"_checksums.txt", "-checksums.txt", ".checksums.txt"}
+
+// checksumExts are the extensions added to the asset name for checksums of a single asset
+var checksumExts = []string{".sha256", ".sha256sum", ".sha512", ".sha512sum"}
+
+// ListVerifiableReleases returns the releases matching the constraint which have both an
+// asset for the platform and a checksums asset, so that the installed asset can be verified.
+// A checksums asset is a file such as checksums.txt, SHA256SUMS, <project>_<version>_checksums.txt
+// or the asset name with a .sha256 or .sha512 extension
+func (v *VersionsImpl) ListVerifiableReleases(constraint string) (map[string]string, error) {
+	if v.options.Frozen {
+		return nil, ErrFrozen
+	}
+
+	constraint = v.constraint(constraint)
+
+	gr, err := v.listGitHubReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	releases := map[string]string{}
+
+	for _, g := range gr {
+		d, err := v.decide(g, constraint)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.skip != "" || !hasChecksumAsset(d.url, g.Assets) {
+			continue
+		}
+
+		releases[d.tag] = d.url
+	}
+
+	return releases, nil
+}
+
+// hasChecksumAsset returns true when the assets contain a checksums file for the asset
+// with the given download URL
+func hasChecksumAsset(url string, assets []github.ReleaseAsset) bool {
+	name := strings.ToLower(url[strings.LastIndex(url, "/")+1:])
+
+	for _, a := range assets {
+		if isChecksumAsset(name, strings.ToLower(a.GetName())) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isChecksumAsset returns true when the asset with the lower case name contains the checksum
+// for the asset named asset
+func isChecksumAsset(asset, name string) bool {
+	for _, n := range checksumFileNames {
+		if name == n {
+			return true
+		}
+	}
+
+	for _, s := range checksumFileSuffixes {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+
+	for _, e := range checksumExts {
+		if name == asset+e {
+			return true
+		}
+	}
+
+	return false
+}
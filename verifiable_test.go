@@ -0,0 +1,55 @@
+package gvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestListVerifiableReleasesRequiresChecksumAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.4", "fake-service-linux", "fake-service-linux.sha256")
+	s.addRelease("v0.14.3", "fake-service-linux", "fake-service_0.14.3_checksums.txt")
+	s.addRelease("v0.14.2", "fake-service-linux", "SHA256SUMS")
+	s.addRelease("v0.14.1", "fake-service-linux", "README.md")
+	s.addRelease("v0.14.0", "checksums.txt")
+
+	r, err := v.ListVerifiableReleases("")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 3)
+	assert.Equal(t, s.URL+"/download/v0.14.4/fake-service-linux", r["v0.14.4"])
+	assert.Contains(t, r, "v0.14.3")
+	assert.Contains(t, r, "v0.14.2")
+}
+
+func TestListVerifiableReleasesIgnoresChecksumForOtherAsset(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux", "fake-service-osx.sha256")
+
+	r, err := v.ListVerifiableReleases("")
+	assert.NoError(t, err)
+
+	assert.Empty(t, r)
+}
+
+func TestListVerifiableReleasesFiltersByConstraint(t *testing.T) {
+	_, v, s := setupStub(t)
+	s.addRelease("v1.0.0", "fake-service-linux", "checksums.txt")
+	s.addRelease("v0.14.1", "fake-service-linux", "checksums.txt")
+
+	r, err := v.ListVerifiableReleases("~0.14.0")
+	assert.NoError(t, err)
+
+	assert.Len(t, r, 1)
+	assert.Contains(t, r, "v0.14.1")
+}
+
+func TestListVerifiableReleasesReturnsErrorWhenFrozen(t *testing.T) {
+	_, v, _ := setupStub(t)
+	v.options.Frozen = true
+
+	_, err := v.ListVerifiableReleases("")
+	assert.True(t, xerrors.Is(err, ErrFrozen))
+}
@@ -0,0 +1,95 @@
+package gvm
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// defaultVersionFileName is the file read by ResolveFromDir when VersionFileName is not set
+const defaultVersionFileName = ".tool-version"
+
+// ErrNoVersionFile is returned when no version file is found
+var ErrNoVersionFile = xerrors.New("No version file found")
+
+// ErrNoMatchingRelease is returned when there is no release matching a constraint
+var ErrNoMatchingRelease = xerrors.New("No release matches the constraint")
+
+// ResolveFromDir looks for VersionFileName in dir and each of its parents up to the
+// file system root, the first line of the closest file is read as a version or
+// semantic version constraint and the tag of the latest matching release returned.
+// Blank lines and lines starting with # are ignored.
+// Returns ErrNoVersionFile when there is no version file and ErrNoMatchingRelease
+// when no release matches the version in the file
+func (v *VersionsImpl) ResolveFromDir(dir string) (string, error) {
+	fp, err := v.findVersionFile(dir)
+	if err != nil {
+		return "", err
+	}
+
+	constraint, err := readVersionFile(fp)
+	if err != nil {
+		return "", err
+	}
+
+	tag, _, err := v.GetLatestReleaseURL(constraint)
+	if err != nil {
+		return "", err
+	}
+
+	if tag == "" {
+		return "", xerrors.Errorf("%w: %s from %s", ErrNoMatchingRelease, constraint, fp)
+	}
+
+	return tag, nil
+}
+
+// findVersionFile returns the path of the closest version file to dir
+func (v *VersionsImpl) findVersionFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to determine absolute path: %w", err)
+	}
+
+	for {
+		fp := filepath.Join(dir, v.options.VersionFileName)
+		if fi, err := os.Stat(fp); err == nil && !fi.IsDir() {
+			return fp, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNoVersionFile
+		}
+
+		dir = parent
+	}
+}
+
+// readVersionFile returns the first line of the version file which is not blank or a comment
+func readVersionFile(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", xerrors.Errorf("Unable to read version file: %w", err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+
+		return l, nil
+	}
+
+	if err := s.Err(); err != nil {
+		return "", xerrors.Errorf("Unable to read version file: %w", err)
+	}
+
+	return "", xerrors.Errorf("Version file %s does not contain a version", fp)
+}
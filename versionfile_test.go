@@ -0,0 +1,61 @@
+package gvm
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestResolveFromDirReadsVersionFileAtDepth(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.2", "fake-service-linux")
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	project := path.Join(tmp, "project")
+	nested := path.Join(project, "a", "b", "c")
+	os.MkdirAll(nested, os.ModePerm)
+	ioutil.WriteFile(path.Join(project, ".tool-version"), []byte("# pinned\n~0.14.0\n"), 0644)
+
+	for _, dir := range []string{project, path.Join(project, "a"), nested} {
+		tag, err := v.ResolveFromDir(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, "v0.14.2", tag, dir)
+	}
+}
+
+func TestResolveFromDirUsesClosestVersionFile(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	s.addRelease("v0.13.0", "fake-service-linux")
+
+	nested := path.Join(tmp, "project", "a")
+	os.MkdirAll(nested, os.ModePerm)
+	ioutil.WriteFile(path.Join(tmp, "project", ".tool-version"), []byte("0.14.1"), 0644)
+	ioutil.WriteFile(path.Join(nested, ".tool-version"), []byte("v0.13.0"), 0644)
+
+	tag, err := v.ResolveFromDir(nested)
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.13.0", tag)
+}
+
+func TestResolveFromDirReturnsErrorWhenNoVersionFile(t *testing.T) {
+	tmp, v := setup(t)
+	v.options.VersionFileName = ".fake-service-version-test"
+
+	_, err := v.ResolveFromDir(tmp)
+	assert.Equal(t, ErrNoVersionFile, err)
+}
+
+func TestResolveFromDirReturnsErrorWhenNoMatchingRelease(t *testing.T) {
+	tmp, v, s := setupStub(t)
+	s.addRelease("v0.14.1", "fake-service-linux")
+	ioutil.WriteFile(path.Join(tmp, ".tool-version"), []byte("~1.0.0"), 0644)
+
+	_, err := v.ResolveFromDir(tmp)
+	assert.True(t, xerrors.Is(err, ErrNoMatchingRelease))
+}